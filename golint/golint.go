@@ -8,6 +8,7 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"go/build"
@@ -19,7 +20,20 @@ import (
 	"github.com/golang/lint"
 )
 
-var minConfidence = flag.Float64("min_confidence", 0.8, "minimum confidence of a problem to print it")
+var (
+	minConfidence = flag.Float64("min_confidence", 0.8, "minimum confidence of a problem to print it")
+	localPrefix   = flag.String("local", "", "comma-separated import path prefixes of local packages, whose imports should be grouped after third-party ones")
+	errorFuncs    = flag.String("error_funcs", "", "comma-separated functions that create an error from a string, such as example.com/xerr.New")
+	properNouns   = flag.String("proper_nouns", "", "comma-separated words that may start an error string capitalized")
+	timeSuffixes  = flag.String("time_suffixes", "", "comma-separated name suffixes that imply a time unit, in addition to the built-in ones")
+	maxNesting    = flag.Int("max_nesting", 5, "deepest nesting of blocks within a function that is not reported")
+	maxComplexity = flag.Int("max_complexity", 0, "largest cyclomatic complexity of an exported function that is not reported; 0 disables the check")
+	maxStatements = flag.Int("max_statements", 0, "largest number of statements of an exported function that is not reported; 0 disables the check")
+	metricsFile   = flag.String("metrics", "", "write the metrics of exported functions as JSON to this file")
+)
+
+// metrics accumulates the function metrics of all linted packages for -metrics.
+var metrics = []lint.FuncMetrics{}
 
 func usage() {
 	fmt.Fprintf(os.Stderr, "Usage of %s:\n", os.Args[0])
@@ -35,6 +49,13 @@ func main() {
 	flag.Usage = usage
 	flag.Parse()
 
+	if *metricsFile == "-" {
+		// Both standard output and standard error have other output.
+		fmt.Fprintln(os.Stderr, "-metrics needs a file name")
+		flag.Usage()
+		os.Exit(2)
+	}
+
 	switch flag.NArg() {
 	case 0:
 		lintDir(".")
@@ -56,6 +77,23 @@ func main() {
 	default:
 		lintFiles(flag.Args()...)
 	}
+
+	if *metricsFile != "" {
+		writeMetrics(*metricsFile)
+	}
+}
+
+func writeMetrics(filename string) {
+	data, err := json.MarshalIndent(metrics, "", "\t")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	data = append(data, '\n')
+	if err = ioutil.WriteFile(filename, data, 0666); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 }
 
 func isDir(filename string) bool {
@@ -79,7 +117,21 @@ func lintFiles(filenames ...string) {
 		files[filename] = src
 	}
 
-	l := new(lint.Linter)
+	l := &lint.Linter{
+		LocalPrefix:   *localPrefix,
+		MaxNesting:    *maxNesting,
+		MaxComplexity: *maxComplexity,
+		MaxStatements: *maxStatements,
+	}
+	if *errorFuncs != "" {
+		l.ErrorFuncs = strings.Split(*errorFuncs, ",")
+	}
+	if *properNouns != "" {
+		l.ProperNouns = strings.Split(*properNouns, ",")
+	}
+	if *timeSuffixes != "" {
+		l.TimeSuffixes = strings.Split(*timeSuffixes, ",")
+	}
 	ps, err := l.LintFiles(files)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "%v\n", err)
@@ -90,6 +142,14 @@ func lintFiles(filenames ...string) {
 			fmt.Printf("%v: %s\n", p.Position, p.Text)
 		}
 	}
+	if *metricsFile != "" {
+		ms, err := l.Metrics(files)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			return
+		}
+		metrics = append(metrics, ms...)
+	}
 }
 
 func lintDir(dirname string) {
@@ -21,6 +21,7 @@ import (
 	"unicode"
 	"unicode/utf8"
 
+	"golang.org/x/tools/go/exact"
 	"golang.org/x/tools/go/gcimporter"
 	"golang.org/x/tools/go/types"
 )
@@ -28,9 +29,40 @@ import (
 const styleGuideBase = "https://golang.org/wiki/CodeReviewComments"
 
 // A Linter lints Go source code.
+// Its fields configure some of the checks; the zero value is ready to use.
 type Linter struct {
+	// LocalPrefix is a comma-separated list of import path prefixes.
+	// Imports matching one of them should be grouped after third-party imports,
+	// as done by goimports -local.
+	LocalPrefix string
+
+	// ErrorFuncs lists additional functions that create an error from a string,
+	// given as an import path and function name such as "example.com/xerr.New".
+	// Their first argument is checked as an error string.
+	ErrorFuncs []string
+
+	// ProperNouns lists words that may start an error string capitalized,
+	// in addition to exported identifiers of the package and its imports.
+	ProperNouns []string
+
+	// TimeSuffixes lists name suffixes that imply a time unit,
+	// in addition to the built-in ones such as "Secs" and "Ms".
+	TimeSuffixes []string
+
+	// MaxNesting is the deepest nesting of blocks within a function
+	// that is not reported. If it is zero, defaultMaxNesting is used.
+	MaxNesting int
+
+	// MaxComplexity and MaxStatements are the largest cyclomatic complexity
+	// and number of statements of an exported function that are not reported.
+	// If they are zero, the respective metric is not checked.
+	MaxComplexity int
+	MaxStatements int
 }
 
+// defaultMaxNesting is the default value of Linter.MaxNesting.
+const defaultMaxNesting = 5
+
 // Problem represents a problem in some source code.
 type Problem struct {
 	Position   token.Position // position in source file
@@ -40,9 +72,20 @@ type Problem struct {
 	LineText   string         // the source line
 	Category   string         // a short name for the general category of the problem
 
-	// If the problem has a suggested fix (the minority case),
-	// ReplacementLine is a full replacement for the relevant line of the source file.
+	// If the problem has a suggested fix (the minority case), it is held by exactly one
+	// of ReplacementLine and Edits. If the fix only changes the line of Position,
+	// ReplacementLine is a full replacement for that line of the source file.
 	ReplacementLine string
+
+	// If the suggested fix changes more than one line,
+	// Edits holds it as a list of non-overlapping replacements in source order.
+	Edits []Edit
+}
+
+// An Edit replaces the source text in [Pos, End) with New.
+type Edit struct {
+	Pos, End token.Position
+	New      string
 }
 
 func (p *Problem) String() string {
@@ -81,12 +124,52 @@ func (l *Linter) Lint(filename string, src []byte) ([]Problem, error) {
 // LintFiles lints a set of files of a single package.
 // The argument is a map of filename to source.
 func (l *Linter) LintFiles(files map[string][]byte) ([]Problem, error) {
+	pkg, err := l.parseFiles(files)
+	if pkg == nil {
+		return nil, err
+	}
+	return pkg.lint(), nil
+}
+
+// Metrics computes the metrics of the exported functions and methods
+// in a set of files of a single package, in source order.
+// The argument is a map of filename to source, as for LintFiles.
+func (l *Linter) Metrics(files map[string][]byte) ([]FuncMetrics, error) {
+	pkg, err := l.parseFiles(files)
+	if pkg == nil {
+		return nil, err
+	}
+	var filenames []string
+	for filename := range pkg.files {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+	var ms []FuncMetrics
+	for _, filename := range filenames {
+		f := pkg.files[filename]
+		f.walk(func(n ast.Node) bool {
+			if fn, ok := n.(*ast.FuncDecl); ok {
+				if m, ok := f.funcMetrics(fn); ok {
+					ms = append(ms, m)
+				}
+				return false
+			}
+			return true
+		})
+	}
+	return ms, nil
+}
+
+// parseFiles parses a set of files of a single package.
+// It returns a nil pkg if there are no files or they cannot be parsed.
+func (l *Linter) parseFiles(files map[string][]byte) (*pkg, error) {
 	if len(files) == 0 {
 		return nil, nil
 	}
 	pkg := &pkg{
-		fset:  token.NewFileSet(),
-		files: make(map[string]*file),
+		linter: l,
+		fset:   token.NewFileSet(),
+		files:  make(map[string]*file),
 	}
 	var pkgName string
 	for filename, src := range files {
@@ -107,13 +190,22 @@ func (l *Linter) LintFiles(files map[string][]byte) ([]Problem, error) {
 			filename: filename,
 		}
 	}
-	return pkg.lint(), nil
+	return pkg, nil
+}
+
+// FuncMetrics holds the size and complexity of an exported function or method.
+type FuncMetrics struct {
+	Position   token.Position // position of the function name
+	Name       string         // function name, or "T.M" for method M of type T
+	Complexity int            // cyclomatic complexity
+	Statements int            // number of statements in the body's blocks
 }
 
 // pkg represents a package being linted.
 type pkg struct {
-	fset  *token.FileSet
-	files map[string]*file
+	linter *Linter
+	fset   *token.FileSet
+	files  map[string]*file
 
 	typesPkg  *types.Package
 	typesInfo *types.Info
@@ -122,6 +214,12 @@ type pkg struct {
 	sortable map[string]bool
 	// main is whether this is a "main" package.
 	main bool
+	// ifaceMethods maps method names to the signatures of the methods with that name
+	// of the interfaces declared in the package and its imports.
+	ifaceMethods map[string][]types.Type
+	// funcValues is the set of functions and methods of the package
+	// that are used other than by calling them, such as in an assignment.
+	funcValues map[types.Object]bool
 
 	problems []Problem
 }
@@ -147,11 +245,14 @@ func (p *pkg) lint() []Problem {
 	}
 
 	p.scanSortable()
+	p.scanInterfaceMethods()
+	p.scanFuncValues()
 	p.main = p.isMain()
 
 	for _, f := range p.files {
 		f.lint()
 	}
+	p.lintUnusedDecls()
 
 	sort.Sort(byPosition(p.problems))
 
@@ -187,6 +288,18 @@ func (f *file) lint() {
 	f.lintErrorReturn()
 	f.lintUnexportedReturn()
 	f.lintTimeNames()
+	f.lintPanics()
+	f.lintGoroutineLifetimes()
+	f.lintMutexes()
+	f.lintStringConcatInLoops()
+	f.lintSimplify()
+	f.lintRedundantTypes()
+	f.lintShadowing()
+	f.lintTests()
+	f.lintTestMessages()
+	f.lintNesting()
+	f.lintComplexity()
+	f.lintUnusedParams()
 }
 
 type link string
@@ -243,10 +356,11 @@ func (p *pkg) typeCheck() error {
 		Import: gcImporter,
 	}
 	info := &types.Info{
-		Types:  make(map[ast.Expr]types.TypeAndValue),
-		Defs:   make(map[*ast.Ident]types.Object),
-		Uses:   make(map[*ast.Ident]types.Object),
-		Scopes: make(map[ast.Node]*types.Scope),
+		Types:     make(map[ast.Expr]types.TypeAndValue),
+		Defs:      make(map[*ast.Ident]types.Object),
+		Uses:      make(map[*ast.Ident]types.Object),
+		Scopes:    make(map[ast.Node]*types.Scope),
+		Implicits: make(map[ast.Node]types.Object),
 	}
 	var anyFile *file
 	var astFiles []*ast.File
@@ -298,6 +412,145 @@ func (p *pkg) scopeOf(id *ast.Ident) *types.Scope {
 	return scope
 }
 
+func (p *pkg) scanInterfaceMethods() {
+	p.ifaceMethods = make(map[string][]types.Type)
+	if p.typesPkg == nil {
+		return
+	}
+	for _, tp := range append([]*types.Package{p.typesPkg}, p.typesPkg.Imports()...) {
+		scope := tp.Scope()
+		for _, name := range scope.Names() {
+			tn, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok {
+				continue
+			}
+			iface, ok := tn.Type().Underlying().(*types.Interface)
+			if !ok {
+				continue
+			}
+			for i := 0; i < iface.NumMethods(); i++ {
+				m := iface.Method(i)
+				p.ifaceMethods[m.Name()] = append(p.ifaceMethods[m.Name()], m.Type())
+			}
+		}
+	}
+}
+
+// satisfiesInterface reports whether fn has the name and signature
+// of a method of an interface declared in the package or its imports.
+func (p *pkg) satisfiesInterface(fn *types.Func) bool {
+	for _, sig := range p.ifaceMethods[fn.Name()] {
+		// Identical ignores the receivers of signatures.
+		if types.Identical(sig, fn.Type()) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *pkg) scanFuncValues() {
+	p.funcValues = make(map[types.Object]bool)
+	if p.typesInfo == nil {
+		return
+	}
+	for _, f := range p.files {
+		called := make(map[*ast.Ident]bool)
+		f.walk(func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.CallExpr:
+				switch fun := n.Fun.(type) {
+				case *ast.Ident:
+					called[fun] = true
+				case *ast.SelectorExpr:
+					called[fun.Sel] = true
+				}
+			case *ast.Ident:
+				if fn, ok := p.typesInfo.Uses[n].(*types.Func); ok && !called[n] {
+					p.funcValues[fn] = true
+				}
+			}
+			return true
+		})
+	}
+}
+
+// lintUnusedDecls examines the top-level declarations of the package.
+// It complains about unexported functions, types, constants and variables
+// that are not used in any file of the package.
+// Other files, such as those excluded by build constraints, may still use them,
+// so these problems are reported with lower confidence.
+func (p *pkg) lintUnusedDecls() {
+	if p.typesInfo == nil {
+		return
+	}
+	// Record the uses of each object outside its own declaration,
+	// along with the names referenced by linkname and cgo export directives.
+	uses := make(map[types.Object]bool)
+	directives := make(map[string]bool)
+	for _, f := range p.files {
+		for _, decl := range f.f.Decls {
+			ast.Walk(walker(func(n ast.Node) bool {
+				id, ok := n.(*ast.Ident)
+				if !ok {
+					return true
+				}
+				if obj := p.typesInfo.Uses[id]; obj != nil && (obj.Pos() < decl.Pos() || obj.Pos() >= decl.End()) {
+					uses[obj] = true
+				}
+				return true
+			}), decl)
+		}
+		for _, cg := range f.f.Comments {
+			for _, c := range cg.List {
+				fields := strings.Fields(c.Text)
+				if len(fields) >= 2 && (fields[0] == "//go:linkname" || fields[0] == "//export") {
+					directives[fields[1]] = true
+				}
+			}
+		}
+	}
+
+	for _, f := range p.files {
+		for _, decl := range f.f.Decls {
+			var ids []*ast.Ident
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				if d.Recv == nil {
+					ids = append(ids, d.Name)
+				}
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					switch s := spec.(type) {
+					case *ast.TypeSpec:
+						ids = append(ids, s.Name)
+					case *ast.ValueSpec:
+						ids = append(ids, s.Names...)
+					}
+				}
+			}
+			for _, id := range ids {
+				if ast.IsExported(id.Name) || id.Name == "_" || id.Name == "init" || id.Name == "main" || directives[id.Name] {
+					continue
+				}
+				obj := p.typesInfo.Defs[id]
+				if obj == nil || uses[obj] {
+					continue
+				}
+				thing := "var"
+				switch obj.(type) {
+				case *types.Func:
+					thing = "func"
+				case *types.TypeName:
+					thing = "type"
+				case *types.Const:
+					thing = "const"
+				}
+				f.errorf(id, 0.7, category("dead-code"), "%s %s is unused", thing, id.Name)
+			}
+		}
+	}
+}
+
 func (p *pkg) scanSortable() {
 	p.sortable = make(map[string]bool)
 
@@ -435,16 +688,199 @@ func (f *file) lintBlankImports() {
 }
 
 // lintImports examines import blocks.
+// It complains about dot imports, and about import groups that mix
+// or misorder standard library, third-party and local imports.
+// Import renames are checked by lintNames.
 func (f *file) lintImports() {
-
-	for i, is := range f.f.Imports {
-		_ = i
+	for _, is := range f.f.Imports {
 		if is.Name != nil && is.Name.Name == "." && !f.isTest() {
 			f.errorf(is, 1, link(styleGuideBase+"#import-dot"), category("imports"), "should not use dot imports")
 		}
+	}
+
+	for _, decl := range f.f.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.IMPORT || !gd.Lparen.IsValid() {
+			continue
+		}
+		f.lintImportGroups(gd)
+	}
+}
+
+// Import groups, in the order they should appear.
+const (
+	stdImport = iota
+	thirdPartyImport
+	localImport
+)
+
+var importGroupNames = [...]string{
+	stdImport:        "standard library",
+	thirdPartyImport: "third-party",
+	localImport:      "local",
+}
+
+// importGroup returns which group the import of path belongs in.
+func (f *file) importGroup(path string) int {
+	for _, prefix := range strings.Split(f.pkg.linter.LocalPrefix, ",") {
+		if prefix != "" && strings.HasPrefix(path, prefix) {
+			return localImport
+		}
+	}
+	if elem := strings.SplitN(path, "/", 2)[0]; !strings.Contains(elem, ".") {
+		return stdImport
+	}
+	return thirdPartyImport
+}
+
+// isUnnecessaryRename reports whether is renames an import to the name the package already has.
+func (f *file) isUnnecessaryRename(is *ast.ImportSpec) bool {
+	return is.Name != nil && f.importedName(is) == is.Name.Name
+}
+
+// importedName returns the name declared by the package that is imports.
+func (f *file) importedName(is *ast.ImportSpec) string {
+	if pkg := f.importedPkg(is); pkg != nil {
+		return pkg.Name()
+	}
+	// Type checking failed; guess at the package name.
+	path, _ := strconv.Unquote(is.Path.Value)
+	return path[strings.LastIndex(path, "/")+1:]
+}
+
+// importedPkg returns the package that is imports,
+// or nil if type checking didn't resolve it.
+func (f *file) importedPkg(is *ast.ImportSpec) *types.Package {
+	var obj types.Object
+	if is.Name != nil && is.Name.Name != "." {
+		obj = f.pkg.typesInfo.Defs[is.Name]
+	} else {
+		// Unrenamed and dot imports.
+		obj = f.pkg.typesInfo.Implicits[is]
+	}
+	if pn, ok := obj.(*types.PkgName); ok {
+		return pn.Imported()
+	}
+	return nil
+}
+
+// lintImportGroups complains if the blank-line separated groups of imports in gd
+// mix imports from different groups, or are out of order.
+// The suggested fix rewrites the whole block.
+func (f *file) lintImportGroups(gd *ast.GenDecl) {
+	type spec struct {
+		is    *ast.ImportSpec
+		group int
+	}
+	// Split the specs into blocks at blank lines.
+	var blocks [][]spec
+	lastLine := 0
+	for _, s := range gd.Specs {
+		is := s.(*ast.ImportSpec)
+		path, _ := strconv.Unquote(is.Path.Value)
+		if path == "C" {
+			// cgo's pseudo-package has its own rules.
+			return
+		}
+		start := is.Pos()
+		if is.Doc != nil {
+			start = is.Doc.Pos()
+		}
+		if len(blocks) == 0 || f.fset.Position(start).Line > lastLine+1 {
+			blocks = append(blocks, nil)
+		}
+		blocks[len(blocks)-1] = append(blocks[len(blocks)-1], spec{is, f.importGroup(path)})
+		lastLine = f.fset.Position(is.End()).Line
+	}
+
+	var bad *ast.ImportSpec
+	var msg string
+	maxGroup := -1
+blockLoop:
+	for _, block := range blocks {
+		for _, s := range block[1:] {
+			if s.group != block[0].group {
+				bad = s.is
+				msg = fmt.Sprintf("%s imports should be in a separate group from %s imports", importGroupNames[s.group], importGroupNames[block[0].group])
+				break blockLoop
+			}
+		}
+		g := block[0].group
+		if g < maxGroup {
+			bad = block[0].is
+			msg = fmt.Sprintf("%s imports should come before %s imports", importGroupNames[g], importGroupNames[maxGroup])
+			break
+		}
+		maxGroup = g
+	}
+	if bad == nil {
+		return
+	}
+	p := f.errorf(bad, 0.7, category("imports"), msg)
+
+	// Comments that are not attached to a spec would be lost
+	// by rendering the block again, so offer no fix.
+	attached := make(map[*ast.CommentGroup]bool)
+	for _, s := range gd.Specs {
+		is := s.(*ast.ImportSpec)
+		attached[is.Doc] = true
+		attached[is.Comment] = true
+	}
+	for _, cg := range f.f.Comments {
+		if gd.Lparen < cg.Pos() && cg.End() < gd.Rparen && !attached[cg] {
+			return
+		}
+	}
 
+	// Render the whole block with the groups in order.
+	var groups [len(importGroupNames)][]*ast.ImportSpec
+	for _, block := range blocks {
+		for _, s := range block {
+			groups[s.group] = append(groups[s.group], s.is)
+		}
+	}
+	var parts []string
+	for _, g := range groups {
+		if len(g) == 0 {
+			continue
+		}
+		sort.Sort(byImportPath(g))
+		var buf bytes.Buffer
+		for i, is := range g {
+			if i > 0 {
+				buf.WriteString("\n")
+			}
+			if is.Doc != nil {
+				for _, c := range is.Doc.List {
+					fmt.Fprintf(&buf, "\t%s\n", c.Text)
+				}
+			}
+			buf.WriteString("\t")
+			if is.Name != nil && !f.isUnnecessaryRename(is) {
+				buf.WriteString(is.Name.Name + " ")
+			}
+			buf.WriteString(is.Path.Value)
+			if is.Comment != nil {
+				for _, c := range is.Comment.List {
+					buf.WriteString(" " + c.Text)
+				}
+			}
+		}
+		parts = append(parts, buf.String())
 	}
+	f.setFix(p, []Edit{f.edit(gd.Lparen, gd.Rparen+1, "(\n"+strings.Join(parts, "\n\n")+"\n)")})
+}
+
+// byImportPath sorts import specs by their import path.
+type byImportPath []*ast.ImportSpec
+
+func (p byImportPath) Len() int      { return len(p) }
+func (p byImportPath) Swap(i, j int) { p[i], p[j] = p[j], p[i] }
 
+func (p byImportPath) Less(i, j int) bool {
+	pi, _ := strconv.Unquote(p[i].Path.Value)
+	pj, _ := strconv.Unquote(p[j].Path.Value)
+	return pi < pj
 }
 
 const docCommentsLink = styleGuideBase + "#doc-comments"
@@ -575,6 +1011,9 @@ func (f *file) lintNames() {
 			checkList(v.Type.Results, thing+" result")
 		case *ast.GenDecl:
 			if v.Tok == token.IMPORT {
+				for _, spec := range v.Specs {
+					f.lintImportName(spec.(*ast.ImportSpec))
+				}
 				return true
 			}
 			var thing string
@@ -628,95 +1067,456 @@ func (f *file) lintNames() {
 	})
 }
 
-// lintName returns a different name if it should be different.
-func lintName(name string) (should string) {
-	// Fast path for simple cases: "_" and all lowercase.
-	if name == "_" {
-		return name
+// lintShadowing examines declarations.
+// It complains about any that shadow a builtin or an imported package name.
+// Loop variables only live for the loop, so they are reported with lower confidence.
+func (f *file) lintShadowing() {
+	if f.pkg.typesInfo == nil {
+		return
 	}
-	allLower := true
-	for _, r := range name {
-		if !unicode.IsLower(r) {
-			allLower = false
-			break
+	loopVars := make(map[*ast.Ident]bool)
+	f.walk(func(node ast.Node) bool {
+		switch v := node.(type) {
+		case *ast.RangeStmt:
+			if v.Tok == token.DEFINE {
+				for _, e := range []ast.Expr{v.Key, v.Value} {
+					if id, ok := e.(*ast.Ident); ok {
+						loopVars[id] = true
+					}
+				}
+			}
+		case *ast.ForStmt:
+			if as, ok := v.Init.(*ast.AssignStmt); ok && as.Tok == token.DEFINE {
+				for _, e := range as.Lhs {
+					if id, ok := e.(*ast.Ident); ok {
+						loopVars[id] = true
+					}
+				}
+			}
 		}
-	}
-	if allLower {
-		return name
-	}
+		return true
+	})
 
-	// Split camelCase at any lower->upper transition, and split on underscores.
-	// Check each word for common initialisms.
-	runes := []rune(name)
-	w, i := 0, 0 // index of start of word, scan
-	for i+1 <= len(runes) {
-		eow := false // whether we hit the end of a word
-		if i+1 == len(runes) {
-			eow = true
-		} else if runes[i+1] == '_' {
-			// underscore; shift the remainder forward over any run of underscores
-			eow = true
-			n := 1
-			for i+n+1 < len(runes) && runes[i+n+1] == '_' {
-				n++
+	f.walk(func(node ast.Node) bool {
+		id, ok := node.(*ast.Ident)
+		if !ok || id.Name == "_" {
+			return true
+		}
+		obj := f.pkg.typesInfo.Defs[id]
+		var thing string
+		switch o := obj.(type) {
+		case *types.Var:
+			if o.IsField() {
+				return true
 			}
-
-			// Leave at most one underscore if the underscore is between two digits
-			if i+n+1 < len(runes) && unicode.IsDigit(runes[i]) && unicode.IsDigit(runes[i+n+1]) {
-				n--
+			thing = "var"
+		case *types.Const:
+			thing = "const"
+		case *types.TypeName:
+			thing = "type"
+		case *types.Func:
+			if o.Type().(*types.Signature).Recv() != nil {
+				// Methods are always qualified.
+				return true
 			}
-
-			copy(runes[i+1:], runes[i+n+1:])
-			runes = runes[:len(runes)-n]
-		} else if unicode.IsLower(runes[i]) && !unicode.IsLower(runes[i+1]) {
-			// lower->non-lower
-			eow = true
+			thing = "func"
+		default:
+			return true
 		}
-		i++
-		if !eow {
-			continue
+		conf := 0.8
+		if loopVars[id] {
+			conf = 0.5
 		}
-
-		// [w,i) is a word.
-		word := string(runes[w:i])
-		if u := strings.ToUpper(word); commonInitialisms[u] {
-			// Keep consistent case, which is lowercase only at the start.
-			if w == 0 && unicode.IsLower(runes[w]) {
-				u = strings.ToLower(u)
+		for scope := f.pkg.scopeOf(id); scope != nil; scope = scope.Parent() {
+			shadowed := scope.Lookup(id.Name)
+			if shadowed == nil || shadowed == obj {
+				continue
 			}
-			// All the common initialisms are ASCII,
-			// so we can replace the bytes exactly.
-			copy(runes[w:], []rune(u))
-		} else if w > 0 && strings.ToLower(word) == word {
-			// already all lowercase, and not the first word, so uppercase the first character.
-			runes[w] = unicode.ToUpper(runes[w])
+			if scope == types.Universe {
+				f.errorf(id, conf, category("naming"), "%s %s shadows the builtin %s", thing, id.Name, id.Name)
+			} else if pn, ok := shadowed.(*types.PkgName); ok {
+				f.errorf(id, conf, category("naming"), "%s %s shadows the import of package %q", thing, id.Name, pn.Imported().Path())
+			}
+			// The nearest declaration is the one shadowed.
+			break
 		}
-		w = i
+		return true
+	})
+}
+
+// lintImportName examines the name that an import is renamed to.
+// Like package names, it should be lower case without underscores,
+// and it shouldn't merely repeat the imported package's name.
+func (f *file) lintImportName(is *ast.ImportSpec) {
+	if is.Name == nil || isBlank(is.Name) || is.Name.Name == "." {
+		return
+	}
+	const ref = styleGuideBase + "#package-names"
+	name, pkgName := is.Name.Name, f.importedName(is)
+	if name == pkgName {
+		f.errorf(is, 0.9, link(ref), category("naming"), "import of %s should not be renamed to %s, which is already its package name", is.Path.Value, name)
+		return
+	}
+	should := strings.ToLower(lintName(name))
+	if should == name {
+		return
+	}
+	suggestion := "rename it to " + should
+	if should == pkgName {
+		suggestion = "drop the rename"
+	}
+	switch {
+	case allCapsRE.MatchString(name):
+		f.errorf(is, 0.8, link(ref), category("naming"), "don't use ALL_CAPS in import name %s; %s", name, suggestion)
+	case strings.Contains(name, "_"):
+		f.errorf(is, 0.9, link(ref), category("naming"), "don't use an underscore in import name %s; %s", name, suggestion)
+	default:
+		f.errorf(is, 0.8, link(ref), category("naming"), "don't use MixedCaps in import name %s; %s", name, suggestion)
 	}
-	return string(runes)
 }
 
-// commonInitialisms is a set of common initialisms.
-// Only add entries that are highly unlikely to be non-initialisms.
-// For instance, "ID" is fine (Freudian code is rare), but "AND" is not.
-var commonInitialisms = map[string]bool{
-	"API":   true,
-	"ASCII": true,
-	"CPU":   true,
-	"CSS":   true,
-	"DNS":   true,
-	"EOF":   true,
-	"GUID":  true,
-	"HTML":  true,
-	"HTTP":  true,
-	"HTTPS": true,
-	"ID":    true,
-	"IP":    true,
-	"JSON":  true,
-	"LHS":   true,
-	"QPS":   true,
-	"RAM":   true,
-	"RHS":   true,
+// lintTests examines the functions of test files.
+// It complains about test, benchmark and example functions with malformed names,
+// examples that refer to identifiers that don't exist,
+// test helpers that don't call Helper, and benchmarks that don't use b.N.
+func (f *file) lintTests() {
+	if !f.isTest() {
+		return
+	}
+	for _, decl := range f.f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv != nil {
+			continue
+		}
+		name := fn.Name.Name
+		switch {
+		case strings.HasPrefix(name, "Test") && f.hasTestingParam(fn, "T"):
+			f.lintTestName(fn, "Test")
+		case strings.HasPrefix(name, "Benchmark") && f.hasTestingParam(fn, "B"):
+			f.lintTestName(fn, "Benchmark")
+			f.lintBenchmarkN(fn)
+		case strings.HasPrefix(name, "Example") && len(fn.Type.Params.List) == 0:
+			f.lintExampleName(fn)
+		default:
+			f.lintTestHelper(fn)
+		}
+	}
+}
+
+// hasTestingParam reports whether fn takes a single *testing.T or *testing.B, as given by name.
+func (f *file) hasTestingParam(fn *ast.FuncDecl, name string) bool {
+	params := fn.Type.Params.List
+	if len(params) != 1 || len(params[0].Names) > 1 {
+		return false
+	}
+	pt, ok := f.pkg.typeOf(params[0].Type).(*types.Pointer)
+	return ok && f.pkg.isNamedType(pt.Elem(), "testing", name)
+}
+
+// lintTestName complains if the name of a test or benchmark function
+// is not its prefix followed by an upper case letter.
+func (f *file) lintTestName(fn *ast.FuncDecl, prefix string) {
+	name := fn.Name.Name
+	rest := name[len(prefix):]
+	if rest == "" {
+		return
+	}
+	first, _ := utf8.DecodeRuneInString(rest)
+	if first != '_' && !unicode.IsLower(first) {
+		return
+	}
+	should := strings.TrimLeft(lintName(rest), "_")
+	if should != "" {
+		r, n := utf8.DecodeRuneInString(should)
+		should = string(unicode.ToUpper(r)) + should[n:]
+	}
+	should = prefix + should
+	if first == '_' {
+		f.errorf(fn.Name, 0.7, category("testing"), "don't use underscores in %s names; %s should be %s", strings.ToLower(prefix), name, should)
+		return
+	}
+	f.errorf(fn.Name, 0.9, category("testing"), "%s %s will not be run by go test, since %s is followed by a lower case letter; it should be %s", strings.ToLower(prefix), name, prefix, should)
+}
+
+// lintExampleName complains if an example function refers to an identifier
+// that the package doesn't declare.
+func (f *file) lintExampleName(fn *ast.FuncDecl) {
+	if f.pkg.typesPkg == nil || strings.HasSuffix(f.f.Name.Name, "_test") {
+		// The identifiers are in a package we haven't checked.
+		return
+	}
+	hasPkgFile := false
+	for _, pf := range f.pkg.files {
+		if !pf.isTest() {
+			hasPkgFile = true
+			break
+		}
+	}
+	if !hasPkgFile {
+		// The identifiers are in package files we haven't seen.
+		return
+	}
+	rest := strings.TrimPrefix(fn.Name.Name, "Example")
+	if rest == "" || strings.HasPrefix(rest, "_") {
+		// Package example, possibly with a suffix.
+		return
+	}
+	parts := strings.Split(rest, "_")
+	// Drop an optional suffix, which starts with a lower case letter.
+	if n := len(parts); n > 1 {
+		if r, _ := utf8.DecodeRuneInString(parts[n-1]); unicode.IsLower(r) {
+			parts = parts[:n-1]
+		}
+	}
+	ident := parts[0]
+	obj := f.pkg.typesPkg.Scope().Lookup(ident)
+	if obj != nil && len(parts) > 1 {
+		// Example of a method.
+		ident += "." + parts[1]
+		if _, ok := obj.(*types.TypeName); ok {
+			obj, _, _ = types.LookupFieldOrMethod(obj.Type(), true, f.pkg.typesPkg, parts[1])
+		} else {
+			obj = nil
+		}
+	}
+	if obj == nil || len(parts) > 2 {
+		f.errorf(fn.Name, 0.8, category("testing"), "%s refers to unknown identifier %s", fn.Name.Name, ident)
+	}
+}
+
+var (
+	expectedActualRE = regexp.MustCompile(`(?i)\b(expected|actual)\b`)
+	wantRE           = regexp.MustCompile(`(?i)\bwant\b`)
+	gotRE            = regexp.MustCompile(`(?i)\bgot\b`)
+)
+
+// lintTestMessages examines the format strings of t.Errorf and t.Fatalf calls,
+// and their equivalents on testing.B and testing.TB.
+// It complains about "expected" and "actual" phrasing,
+// and about reporting what was wanted before what was got.
+func (f *file) lintTestMessages() {
+	f.walk(func(node ast.Node) bool {
+		ce, ok := node.(*ast.CallExpr)
+		if !ok || len(ce.Args) == 0 {
+			return true
+		}
+		se, ok := ce.Fun.(*ast.SelectorExpr)
+		if !ok || (se.Sel.Name != "Errorf" && se.Sel.Name != "Fatalf") {
+			return true
+		}
+		typ := f.pkg.typeOf(se.X)
+		if typ == nil {
+			return true
+		}
+		switch typ.String() {
+		case "*testing.T", "*testing.B", "testing.TB":
+		default:
+			return true
+		}
+		str, ok := ce.Args[0].(*ast.BasicLit)
+		if !ok || str.Kind != token.STRING {
+			return true
+		}
+		s, _ := strconv.Unquote(str.Value)
+		const form = `"Foo(%v) = %v, want %v"`
+		if m := expectedActualRE.FindString(s); m != "" {
+			f.errorf(str, 0.8, link(styleGuideBase+"#useful-test-failures"), category("testing"), "test failure message should report got and want rather than %q; use the form %s", m, form)
+			return true
+		}
+		want, got := wantRE.FindStringIndex(s), gotRE.FindStringIndex(s)
+		if want != nil && got != nil && want[0] < got[0] {
+			f.errorf(str, 0.8, link(styleGuideBase+"#useful-test-failures"), category("testing"), "test failure message should report what it got before what it wants; use the form %s", form)
+		}
+		return true
+	})
+}
+
+// testingMethodsFailing is the set of methods of testing.T and testing.B that report failures.
+var testingMethodsFailing = map[string]bool{
+	"Error":   true,
+	"Errorf":  true,
+	"Fail":    true,
+	"FailNow": true,
+	"Fatal":   true,
+	"Fatalf":  true,
+}
+
+// lintTestHelper complains if fn reports failures through a *testing.T, *testing.B
+// or testing.TB parameter without marking itself as a helper.
+func (f *file) lintTestHelper(fn *ast.FuncDecl) {
+	if fn.Body == nil {
+		return
+	}
+	for _, field := range fn.Type.Params.List {
+		typ := f.pkg.typeOf(field.Type)
+		if pt, ok := typ.(*types.Pointer); ok {
+			typ = pt.Elem()
+		}
+		if !f.pkg.isNamedType(typ, "testing", "T") && !f.pkg.isNamedType(typ, "testing", "B") && !f.pkg.isNamedType(typ, "testing", "TB") {
+			continue
+		}
+		for _, id := range field.Names {
+			obj := f.pkg.typesInfo.Defs[id]
+			if obj == nil {
+				continue
+			}
+			fails, helper := false, false
+			ast.Walk(walker(func(n ast.Node) bool {
+				ce, ok := n.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				se, ok := ce.Fun.(*ast.SelectorExpr)
+				if !ok {
+					return true
+				}
+				if x, ok := se.X.(*ast.Ident); !ok || f.pkg.typesInfo.Uses[x] != obj {
+					return true
+				}
+				if se.Sel.Name == "Helper" {
+					helper = true
+				}
+				if testingMethodsFailing[se.Sel.Name] {
+					fails = true
+				}
+				return true
+			}), fn.Body)
+			if fails && !helper {
+				f.errorf(fn.Name, 0.6, category("testing"), "test helper %s should call %s.Helper() so that failures are reported at its caller", fn.Name.Name, id.Name)
+			}
+		}
+	}
+}
+
+// lintBenchmarkN complains if the benchmark fn never uses b.N or b.Loop,
+// runs sub-benchmarks, or passes b to another function.
+func (f *file) lintBenchmarkN(fn *ast.FuncDecl) {
+	if fn.Body == nil {
+		return
+	}
+	id := fn.Type.Params.List[0].Names
+	if len(id) == 0 || isBlank(id[0]) {
+		// b isn't even named.
+		f.errorf(fn.Name, 0.8, category("testing"), "benchmark %s never uses b.N, so it doesn't measure a number of iterations", fn.Name.Name)
+		return
+	}
+	b := id[0]
+	obj := f.pkg.typesInfo.Defs[b]
+	isB := func(expr ast.Expr) bool {
+		x, ok := expr.(*ast.Ident)
+		return ok && obj != nil && f.pkg.typesInfo.Uses[x] == obj
+	}
+	used := false
+	ast.Walk(walker(func(n ast.Node) bool {
+		switch v := n.(type) {
+		case *ast.SelectorExpr:
+			if isB(v.X) && (v.Sel.Name == "N" || v.Sel.Name == "Loop" || v.Sel.Name == "Run" || v.Sel.Name == "RunParallel") {
+				used = true
+			}
+		case *ast.CallExpr:
+			for _, arg := range v.Args {
+				if isB(arg) {
+					// Some other function is doing the benchmarking.
+					used = true
+				}
+			}
+		}
+		return !used
+	}), fn.Body)
+	if !used {
+		f.errorf(fn.Name, 0.8, category("testing"), "benchmark %s never uses %s.N, so it doesn't measure a number of iterations", fn.Name.Name, b.Name)
+	}
+}
+
+// lintName returns a different name if it should be different.
+func lintName(name string) (should string) {
+	// Fast path for simple cases: "_" and all lowercase.
+	if name == "_" {
+		return name
+	}
+	allLower := true
+	for _, r := range name {
+		if !unicode.IsLower(r) {
+			allLower = false
+			break
+		}
+	}
+	if allLower {
+		return name
+	}
+
+	// Split camelCase at any lower->upper transition, and split on underscores.
+	// Check each word for common initialisms.
+	runes := []rune(name)
+	w, i := 0, 0 // index of start of word, scan
+	for i+1 <= len(runes) {
+		eow := false // whether we hit the end of a word
+		if i+1 == len(runes) {
+			eow = true
+		} else if runes[i+1] == '_' {
+			// underscore; shift the remainder forward over any run of underscores
+			eow = true
+			n := 1
+			for i+n+1 < len(runes) && runes[i+n+1] == '_' {
+				n++
+			}
+
+			// Leave at most one underscore if the underscore is between two digits
+			if i+n+1 < len(runes) && unicode.IsDigit(runes[i]) && unicode.IsDigit(runes[i+n+1]) {
+				n--
+			}
+
+			copy(runes[i+1:], runes[i+n+1:])
+			runes = runes[:len(runes)-n]
+		} else if unicode.IsLower(runes[i]) && !unicode.IsLower(runes[i+1]) {
+			// lower->non-lower
+			eow = true
+		}
+		i++
+		if !eow {
+			continue
+		}
+
+		// [w,i) is a word.
+		word := string(runes[w:i])
+		if u := strings.ToUpper(word); commonInitialisms[u] {
+			// Keep consistent case, which is lowercase only at the start.
+			if w == 0 && unicode.IsLower(runes[w]) {
+				u = strings.ToLower(u)
+			}
+			// All the common initialisms are ASCII,
+			// so we can replace the bytes exactly.
+			copy(runes[w:], []rune(u))
+		} else if w > 0 && strings.ToLower(word) == word {
+			// already all lowercase, and not the first word, so uppercase the first character.
+			runes[w] = unicode.ToUpper(runes[w])
+		}
+		w = i
+	}
+	return string(runes)
+}
+
+// commonInitialisms is a set of common initialisms.
+// Only add entries that are highly unlikely to be non-initialisms.
+// For instance, "ID" is fine (Freudian code is rare), but "AND" is not.
+var commonInitialisms = map[string]bool{
+	"API":   true,
+	"ASCII": true,
+	"CPU":   true,
+	"CSS":   true,
+	"DNS":   true,
+	"EOF":   true,
+	"GUID":  true,
+	"HTML":  true,
+	"HTTP":  true,
+	"HTTPS": true,
+	"ID":    true,
+	"IP":    true,
+	"JSON":  true,
+	"LHS":   true,
+	"QPS":   true,
+	"RAM":   true,
+	"RHS":   true,
 	"RPC":   true,
 	"SLA":   true,
 	"SMTP":  true,
@@ -991,31 +1791,126 @@ func (f *file) lintVarDecls() {
 	})
 }
 
-// lintElses examines else blocks. It complains about any else block whose if block ends in a return.
-func (f *file) lintElses() {
-	// We don't want to flag if { } else if { } else { } constructions.
-	// They will appear as an IfStmt whose Else field is also an IfStmt.
-	// Record such a node so we ignore it when we visit it.
-	ignore := make(map[*ast.IfStmt]bool)
-
+// lintRedundantTypes examines conversions and composite literals.
+// It complains about conversions to the type that the operand already has,
+// and about composite literal elements that repeat the element type
+// of the enclosing literal, as gofmt -s would simplify.
+func (f *file) lintRedundantTypes() {
 	f.walk(func(node ast.Node) bool {
-		ifStmt, ok := node.(*ast.IfStmt)
-		if !ok || ifStmt.Else == nil {
-			return true
+		switch v := node.(type) {
+		case *ast.CallExpr:
+			f.lintRedundantConversion(v)
+		case *ast.CompositeLit:
+			f.lintCompositeLitElts(v)
 		}
-		if ignore[ifStmt] {
-			return true
+		return true
+	})
+}
+
+// lintRedundantConversion complains if ce is a conversion that doesn't change the type of its operand.
+func (f *file) lintRedundantConversion(ce *ast.CallExpr) {
+	if len(ce.Args) != 1 || ce.Ellipsis.IsValid() {
+		return
+	}
+	if tv, ok := f.pkg.typesInfo.Types[ce.Fun]; !ok || !tv.IsType() {
+		return
+	}
+	arg := ce.Args[0]
+	to, from := f.pkg.typeOf(ce.Fun), f.pkg.typeOf(arg)
+	if to == nil || from == nil || !types.Identical(to, from) {
+		return
+	}
+	// An untyped constant gets the type it is converted to,
+	// so converting it is not redundant.
+	if isIdent(arg, "nil") {
+		return
+	}
+	if _, ok := f.isUntypedConst(arg); ok {
+		return
+	}
+	// An explicit floating-point conversion rounds its operand,
+	// which keeps x*y+z from being fused into a single operation.
+	if b, ok := to.Underlying().(*types.Basic); ok && b.Info()&(types.IsFloat|types.IsComplex) != 0 {
+		switch arg.(type) {
+		case *ast.Ident, *ast.SelectorExpr, *ast.BasicLit:
+		default:
+			return
 		}
-		if elseif, ok := ifStmt.Else.(*ast.IfStmt); ok {
-			ignore[elseif] = true
-			return true
+	}
+	should := f.srcText(arg)
+	if _, ok := arg.(*ast.BinaryExpr); ok {
+		should = "(" + should + ")"
+	}
+	p := f.errorf(ce, 0.8, category("simplify"), "should omit conversion of %s to %s; it is already of that type", f.render(arg), f.render(ce.Fun))
+	p.ReplacementLine = f.replaceInLine(ce, should)
+}
+
+// lintCompositeLitElts complains if the elements (or keys) of lit repeat its element (or key) type.
+func (f *file) lintCompositeLitElts(lit *ast.CompositeLit) {
+	typ := f.pkg.typeOf(lit)
+	if typ == nil {
+		return
+	}
+	var key, elem types.Type
+	switch u := typ.Underlying().(type) {
+	case *types.Slice:
+		elem = u.Elem()
+	case *types.Array:
+		elem = u.Elem()
+	case *types.Map:
+		key, elem = u.Key(), u.Elem()
+	default:
+		return
+	}
+	var edits []Edit
+	var first ast.Expr // type of the first redundant element
+	check := func(x ast.Expr, typ types.Type) {
+		if typ == nil {
+			return
 		}
-		if _, ok := ifStmt.Else.(*ast.BlockStmt); !ok {
-			// only care about elses without conditions
-			return true
+		pos := x.Pos()
+		if pt, ok := typ.(*types.Pointer); ok {
+			// &T{...} may be written as {...} when the element type is *T.
+			ue, ok := x.(*ast.UnaryExpr)
+			if !ok || ue.Op != token.AND {
+				return
+			}
+			x, typ = ue.X, pt.Elem()
 		}
-		if len(ifStmt.Body.List) == 0 {
-			return true
+		cl, ok := x.(*ast.CompositeLit)
+		if !ok || cl.Type == nil {
+			return
+		}
+		if t := f.pkg.typeOf(cl); t == nil || !types.Identical(t, typ) {
+			return
+		}
+		if first == nil {
+			first = cl.Type
+		}
+		edits = append(edits, f.edit(pos, cl.Lbrace, ""))
+	}
+	for _, elt := range lit.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			check(kv.Key, key)
+			check(kv.Value, elem)
+			continue
+		}
+		check(elt, elem)
+	}
+	if first == nil {
+		return
+	}
+	p := f.errorf(first, 0.8, category("type-inference"), "should omit type %s from composite literal elements; it is implied by the enclosing literal", f.render(first))
+	f.setFix(p, edits)
+}
+
+// lintElses examines else blocks. It complains about any else block whose if block
+// ends in a terminating statement, such as a return, a break or a call to panic.
+// An if statement that lintSimplify replaces with a single return is left to it.
+func (f *file) lintElses() {
+	f.walkElses(func(ifStmt *ast.IfStmt) {
+		if len(ifStmt.Body.List) == 0 || f.boolReturnIf(ifStmt) != "" {
+			return
 		}
 		shortDecl := false // does the if statement have a ":=" initialization statement?
 		if ifStmt.Init != nil {
@@ -1024,390 +1919,2345 @@ func (f *file) lintElses() {
 			}
 		}
 		lastStmt := ifStmt.Body.List[len(ifStmt.Body.List)-1]
-		if _, ok := lastStmt.(*ast.ReturnStmt); ok {
+		if term := f.terminatingStmt(lastStmt); term != "" {
 			extra := ""
 			if shortDecl {
 				extra = " (move short variable declaration to its own line if necessary)"
 			}
-			f.errorf(ifStmt.Else, 1, link(styleGuideBase+"#indent-error-flow"), category("indent"), "if block ends with a return statement, so drop this else and outdent its block"+extra)
+			p := f.errorf(ifStmt.Else, 1, link(styleGuideBase+"#indent-error-flow"), category("indent"), "if block ends with %s, so drop this else and outdent its block%s", term, extra)
+			if !shortDecl {
+				f.setFix(p, f.outdentElseEdits(ifStmt))
+			}
 		}
-		return true
 	})
 }
 
-// lintRanges examines range clauses. It complains about redundant constructions.
-func (f *file) lintRanges() {
-	f.walk(func(node ast.Node) bool {
-		rs, ok := node.(*ast.RangeStmt)
-		if !ok {
-			return true
-		}
-		if rs.Value == nil {
-			// for x = range m { ... }
-			return true // single var form
+// logTerminating and testingTerminating are the functions and methods
+// of the log and testing packages that do not return to their caller.
+var (
+	logTerminating = map[string]bool{
+		"Fatal":   true,
+		"Fatalf":  true,
+		"Fatalln": true,
+		"Panic":   true,
+		"Panicf":  true,
+		"Panicln": true,
+	}
+	testingTerminating = map[string]bool{
+		"FailNow": true,
+		"Fatal":   true,
+		"Fatalf":  true,
+		"Skip":    true,
+		"SkipNow": true,
+		"Skipf":   true,
+	}
+)
+
+// terminatingStmt describes stmt if control does not continue after it,
+// and returns the empty string otherwise.
+func (f *file) terminatingStmt(stmt ast.Stmt) string {
+	switch s := stmt.(type) {
+	case *ast.ReturnStmt:
+		return "a return statement"
+	case *ast.BranchStmt:
+		if s.Tok != token.FALLTHROUGH {
+			return "a " + s.Tok.String() + " statement"
+		}
+	case *ast.BlockStmt:
+		if len(s.List) > 0 {
+			return f.terminatingStmt(s.List[len(s.List)-1])
+		}
+	case *ast.LabeledStmt:
+		return f.terminatingStmt(s.Stmt)
+	case *ast.ExprStmt:
+		ce, ok := s.X.(*ast.CallExpr)
+		if !ok {
+			return ""
+		}
+		if f.isPanic(ce) {
+			return "a call to panic"
+		}
+		if f.pkg.typesInfo == nil {
+			return ""
+		}
+		sel, ok := ce.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return ""
+		}
+		name := sel.Sel.Name
+		typ := f.pkg.typeOf(sel.X)
+		if p, ok := typ.(*types.Pointer); ok {
+			typ = p.Elem()
+		}
+		switch {
+		case f.isPkgFunc(sel, "os", "Exit"),
+			logTerminating[name] && (f.isPkgFunc(sel, "log", name) || f.pkg.isNamedType(typ, "log", "Logger")),
+			testingTerminating[name] && f.isTestingValue(sel.X):
+			return "a call to " + f.render(sel)
+		}
+	}
+	return ""
+}
+
+// outdentElseEdits returns edits that drop the else of ifStmt and outdent its block.
+// It returns nil if the block is laid out unusually or if outdenting it
+// could change the meaning of its declarations.
+func (f *file) outdentElseEdits(ifStmt *ast.IfStmt) []Edit {
+	els := ifStmt.Else.(*ast.BlockStmt)
+	lbrace, rbrace := f.fset.Position(els.Lbrace), f.fset.Position(els.Rbrace)
+	if f.fset.Position(ifStmt.Body.Rbrace).Line != lbrace.Line || rbrace.Line <= lbrace.Line {
+		return nil
+	}
+	indent := f.indentOf(ifStmt)
+	if strings.TrimSuffix(srcLine(f.src, rbrace), "\n") != indent+"}" {
+		return nil
+	}
+	if strings.TrimSpace(srcLine(f.src, lbrace)[lbrace.Column:]) != "" {
+		// Something follows the opening brace.
+		return nil
+	}
+	if f.pkg.typesInfo == nil {
+		return nil
+	}
+	// The block's declarations move to the enclosing block,
+	// where they may collide with or shadow other declarations.
+	if scope := f.pkg.typesInfo.Scopes[els]; scope != nil {
+		outer := f.pkg.typesInfo.Scopes[ifStmt]
+		for _, name := range scope.Names() {
+			for s := outer.Parent(); s != nil; s = s.Parent() {
+				if s.Lookup(name) != nil {
+					return nil
+				}
+			}
+		}
+	}
+	multiLine := false
+	ast.Walk(walker(func(n ast.Node) bool {
+		if lit, ok := n.(*ast.BasicLit); ok && lit.Kind == token.STRING && strings.Contains(lit.Value, "\n") {
+			multiLine = true
+		}
+		return !multiLine
+	}), els)
+	if multiLine {
+		// Outdenting would change the contents of raw strings.
+		return nil
+	}
+
+	tf := f.fset.File(els.Pos())
+	edits := []Edit{f.edit(ifStmt.Body.Rbrace+1, els.Lbrace+1, "")}
+	end := rbrace.Offset - len(indent) // start of the closing brace's line
+	for off := lbrace.Offset + len(srcLine(f.src, lbrace)) - (lbrace.Column - 1); off < end; {
+		line := srcLine(f.src, token.Position{Offset: off})
+		switch {
+		case strings.HasPrefix(line, indent+"\t"):
+			pos := tf.Pos(off + len(indent))
+			edits = append(edits, f.edit(pos, pos+1, ""))
+		case strings.TrimSpace(line) != "":
+			return nil
+		}
+		off += len(line)
+	}
+	edits = append(edits, f.edit(tf.Pos(end-1), els.Rbrace+1, ""))
+	return edits
+}
+
+// largeIfLines is the number of lines from which the block of an if statement
+// ending a function is worth outdenting by returning early instead.
+const largeIfLines = 8
+
+// lintNesting examines the nesting of function bodies.
+// It complains about a large if block without an else at the end of a function,
+// whose condition could be inverted to return early instead,
+// and about blocks nested more deeply than the linter's MaxNesting.
+func (f *file) lintNesting() {
+	max := f.pkg.linter.MaxNesting
+	if max <= 0 {
+		max = defaultMaxNesting
+	}
+	f.walk(func(n ast.Node) bool {
+		var ft *ast.FuncType
+		var body *ast.BlockStmt
+		switch v := n.(type) {
+		case *ast.FuncDecl:
+			ft, body = v.Type, v.Body
+		case *ast.FuncLit:
+			ft, body = v.Type, v.Body
+		default:
+			return true
+		}
+		if body == nil {
+			return true
+		}
+		if ft.Results == nil {
+			f.lintTrailingIf(body)
+		}
+		f.lintNestingDepth(body, max)
+		return true
+	})
+}
+
+// lintTrailingIf complains if the last statement of body is
+// an if statement without an else whose block is large.
+func (f *file) lintTrailingIf(body *ast.BlockStmt) {
+	if len(body.List) == 0 {
+		return
+	}
+	ifStmt, ok := body.List[len(body.List)-1].(*ast.IfStmt)
+	if !ok || ifStmt.Init != nil || ifStmt.Else != nil {
+		return
+	}
+	lines := f.fset.Position(ifStmt.Body.Rbrace).Line - f.fset.Position(ifStmt.Body.Lbrace).Line - 1
+	if lines < largeIfLines {
+		return
+	}
+	f.errorf(ifStmt, 0.6, link(styleGuideBase+"#indent-error-flow"), category("indent"), "if block ends the function, so invert its condition and return early (if %s { return }) to outdent its %d lines", f.negate(ifStmt.Cond), lines)
+}
+
+// lintNestingDepth complains about the outermost statements in body
+// whose blocks are nested more than max levels deep.
+// Since max is a threshold the user chose, or a generous default,
+// a breach is reported with the default minimum confidence.
+// Function literals are not included; they are examined on their own.
+func (f *file) lintNestingDepth(body *ast.BlockStmt, max int) {
+	var visit func(n ast.Node, depth int)
+	visitIf := func(ifStmt *ast.IfStmt, depth int) {
+		// The blocks of an else-if chain are nested equally deep.
+		for {
+			visit(ifStmt.Body, depth)
+			next, ok := ifStmt.Else.(*ast.IfStmt)
+			if !ok {
+				break
+			}
+			ifStmt = next
+		}
+		if ifStmt.Else != nil {
+			visit(ifStmt.Else, depth)
+		}
+	}
+	visit = func(n ast.Node, depth int) {
+		ast.Walk(walker(func(c ast.Node) bool {
+			switch c := c.(type) {
+			case *ast.FuncLit:
+				return false
+			case *ast.IfStmt, *ast.ForStmt, *ast.RangeStmt, *ast.SwitchStmt, *ast.TypeSwitchStmt, *ast.SelectStmt:
+				if c == n {
+					return true
+				}
+				if depth+1 > max {
+					f.errorf(c, 0.8, link(styleGuideBase+"#indent-error-flow"), category("indent"), "block is nested %d levels deep, more than %d; return early or move code into a separate function to reduce nesting", depth+1, max)
+					return false
+				}
+				if ifStmt, ok := c.(*ast.IfStmt); ok {
+					visitIf(ifStmt, depth+1)
+				} else {
+					visit(c, depth+1)
+				}
+				return false
+			}
+			return true
+		}), n)
+	}
+	visit(body, 0)
+}
+
+// walkElses calls fn for each if statement that has an unconditional else block
+// and is not itself the else of another if statement.
+func (f *file) walkElses(fn func(*ast.IfStmt)) {
+	// We don't want to flag if { } else if { } else { } constructions.
+	// They will appear as an IfStmt whose Else field is also an IfStmt.
+	// Record such a node so we ignore it when we visit it.
+	ignore := make(map[*ast.IfStmt]bool)
+
+	f.walk(func(node ast.Node) bool {
+		ifStmt, ok := node.(*ast.IfStmt)
+		if !ok || ifStmt.Else == nil {
+			return true
+		}
+		if ignore[ifStmt] {
+			return true
+		}
+		if elseif, ok := ifStmt.Else.(*ast.IfStmt); ok {
+			ignore[elseif] = true
+			return true
+		}
+		if _, ok := ifStmt.Else.(*ast.BlockStmt); !ok {
+			// only care about elses without conditions
+			return true
+		}
+		fn(ifStmt)
+		return true
+	})
+}
+
+// lintSimplify examines boolean and comparison expressions.
+// It complains about ones that can be written more simply:
+// comparisons against true or false, negated equality tests,
+// if/else statements that return a boolean literal from each branch,
+// and length tests of strings.
+func (f *file) lintSimplify() {
+	f.walk(func(n ast.Node) bool {
+		switch v := n.(type) {
+		case *ast.BinaryExpr:
+			f.lintBoolCompare(v)
+			f.lintStringLen(v)
+		case *ast.UnaryExpr:
+			if v.Op != token.NOT {
+				return true
+			}
+			pe, ok := v.X.(*ast.ParenExpr)
+			if !ok {
+				return true
+			}
+			be, ok := pe.X.(*ast.BinaryExpr)
+			if !ok || (be.Op != token.EQL && be.Op != token.NEQ) {
+				return true
+			}
+			should := f.negate(be)
+			p := f.errorf(v, 0.9, category("simplify"), "should replace %s with %s", f.render(v), should)
+			p.ReplacementLine = f.replaceInLine(v, should)
+		}
+		return true
+	})
+
+	f.walkElses(func(ifStmt *ast.IfStmt) {
+		should := f.boolReturnIf(ifStmt)
+		if should == "" {
+			return
+		}
+		p := f.errorf(ifStmt, 0.8, category("simplify"), "should replace this if statement with %s", should)
+		f.setFix(p, []Edit{f.edit(ifStmt.Pos(), ifStmt.End(), should)})
+	})
+}
+
+// boolReturnIf returns the return statement that replaces ifStmt
+// if it returns true in one branch and false in the other,
+// or the empty string if it doesn't.
+func (f *file) boolReturnIf(ifStmt *ast.IfStmt) string {
+	els, ok := ifStmt.Else.(*ast.BlockStmt)
+	if ifStmt.Init != nil || !ok {
+		return ""
+	}
+	then, ok1 := f.returnedBool(ifStmt.Body)
+	other, ok2 := f.returnedBool(els)
+	if !ok1 || !ok2 || then == other {
+		return ""
+	}
+	if !then {
+		return "return " + f.negate(ifStmt.Cond)
+	}
+	return "return " + f.srcText(ifStmt.Cond)
+}
+
+// lintBoolCompare complains about a comparison of a bool against true or false.
+func (f *file) lintBoolCompare(be *ast.BinaryExpr) {
+	if be.Op != token.EQL && be.Op != token.NEQ {
+		return
+	}
+	x, lit := be.X, be.Y
+	val, ok := f.boolLiteral(lit)
+	if !ok {
+		x, lit = be.Y, be.X
+		if val, ok = f.boolLiteral(lit); !ok {
+			return
+		}
+	}
+	if typ := f.pkg.typeOf(x); typ == nil || !types.Identical(typ.Underlying(), types.Typ[types.Bool]) {
+		return
+	}
+	should := f.srcText(x)
+	if val != (be.Op == token.EQL) {
+		should = f.negate(x)
+	}
+	p := f.errorf(be, 0.9, category("simplify"), "should omit comparison to bool constant, can be simplified to %s", should)
+	p.ReplacementLine = f.replaceInLine(be, should)
+}
+
+// lintStringLen complains about testing the length of a string against zero
+// instead of comparing the string with "".
+func (f *file) lintStringLen(be *ast.BinaryExpr) {
+	var op string
+	switch be.Op {
+	case token.EQL:
+		op = "=="
+	case token.NEQ, token.GTR:
+		op = "!="
+	default:
+		return
+	}
+	ce, ok := be.X.(*ast.CallExpr)
+	if !ok || !isIdent(ce.Fun, "len") || len(ce.Args) != 1 || !isZero(be.Y) {
+		return
+	}
+	typ := f.pkg.typeOf(ce.Args[0])
+	if typ == nil {
+		return
+	}
+	if b, ok := typ.Underlying().(*types.Basic); !ok || b.Info()&types.IsString == 0 {
+		return
+	}
+	should := f.srcText(ce.Args[0]) + " " + op + ` ""`
+	p := f.errorf(be, 0.5, category("simplify"), "should replace %s with %s", f.render(be), should)
+	p.ReplacementLine = f.replaceInLine(be, should)
+}
+
+// boolLiteral reports whether expr is the predeclared true or false, and which.
+func (f *file) boolLiteral(expr ast.Expr) (val, ok bool) {
+	id, ok := expr.(*ast.Ident)
+	if !ok || (id.Name != "true" && id.Name != "false") {
+		return false, false
+	}
+	if obj := f.pkg.typesInfo.Uses[id]; obj == nil || obj.Parent() != types.Universe {
+		// Shadowed, or we couldn't type check.
+		return false, false
+	}
+	return id.Name == "true", true
+}
+
+// returnedBool reports whether block consists of only a return of true or false, and which.
+func (f *file) returnedBool(block *ast.BlockStmt) (val, ok bool) {
+	if len(block.List) != 1 {
+		return false, false
+	}
+	rs, ok := block.List[0].(*ast.ReturnStmt)
+	if !ok || len(rs.Results) != 1 {
+		return false, false
+	}
+	return f.boolLiteral(rs.Results[0])
+}
+
+// negate returns the source of the logical negation of the boolean expression expr.
+func (f *file) negate(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.UnaryExpr:
+		if e.Op == token.NOT {
+			if pe, ok := e.X.(*ast.ParenExpr); ok {
+				return f.srcText(pe.X)
+			}
+			return f.srcText(e.X)
+		}
+	case *ast.BinaryExpr:
+		switch e.Op {
+		case token.EQL:
+			return f.srcText(e.X) + " != " + f.srcText(e.Y)
+		case token.NEQ:
+			return f.srcText(e.X) + " == " + f.srcText(e.Y)
+		}
+		return "!(" + f.srcText(e) + ")"
+	case *ast.Ident, *ast.SelectorExpr, *ast.CallExpr, *ast.IndexExpr, *ast.ParenExpr:
+		return "!" + f.srcText(e)
+	}
+	return "!(" + f.srcText(expr) + ")"
+}
+
+// lintRanges examines range clauses. It complains about redundant constructions,
+// about indexing the ranged-over value where the value form of range would do,
+// and about counting loops over a slice or array that could be range loops.
+func (f *file) lintRanges() {
+	f.walk(func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.RangeStmt:
+			f.lintRangeValues(node)
+		case *ast.ForStmt:
+			f.lintCountingLoop(node)
+		}
+		return true
+	})
+}
+
+func (f *file) lintRangeValues(rs *ast.RangeStmt) {
+	if rs.Key != nil && isIdent(rs.Key, "_") && (rs.Value == nil || isIdent(rs.Value, "_")) {
+		// for _ = range m { ... }
+		p := f.errorf(rs.Key, 1, category("range-loop"), "should omit values from range; this loop is equivalent to `for range ...`")
+
+		newRS := *rs // shallow copy
+		newRS.Key, newRS.Value = nil, nil
+		p.ReplacementLine = f.firstLineOf(&newRS, rs)
+		return
+	}
+	if rs.Value == nil {
+		// for x = range m { ... }
+		f.lintRangeIndex(rs) // single var form
+		return
+	}
+	if !isIdent(rs.Value, "_") {
+		// for ?, y = range m { ... }
+		return
+	}
+
+	p := f.errorf(rs.Value, 1, category("range-loop"), "should omit 2nd value from range; this loop is equivalent to `for %s %s range ...`", f.render(rs.Key), rs.Tok)
+
+	newRS := *rs // shallow copy
+	newRS.Value = nil
+	p.ReplacementLine = f.firstLineOf(&newRS, rs)
+}
+
+// lintRangeIndex examines a range loop that declares only its index.
+// It complains if the body only uses the index to read elements of the
+// ranged-over value, which the value form of range would provide.
+func (f *file) lintRangeIndex(rs *ast.RangeStmt) {
+	key, ok := rs.Key.(*ast.Ident)
+	if !ok || key.Name == "_" || rs.Tok != token.DEFINE || f.pkg.typesInfo == nil || rootIdent(rs.X) == nil {
+		return
+	}
+	typ := f.pkg.typeOf(rs.X)
+	if typ == nil {
+		return
+	}
+	if p, ok := typ.Underlying().(*types.Pointer); ok {
+		typ = p.Elem()
+	}
+	switch typ.Underlying().(type) {
+	case *types.Slice, *types.Array, *types.Map:
+	default:
+		return
+	}
+	idx := f.pkg.typesInfo.Defs[key]
+	if idx == nil {
+		return
+	}
+	u, ok := f.indexUses(rs.Body, idx, rs.X)
+	if !ok || !u.readOnly || len(u.elems) == 0 {
+		return
+	}
+	p := f.errorf(rs, 0.7, category("range-loop"), "should use the value form of range instead of indexing %s", f.render(u.elems[0]))
+
+	v := f.valueName(rs, rs.Body)
+	if v == "" {
+		return
+	}
+	newRS := *rs // shallow copy
+	newRS.Value = ast.NewIdent(v)
+	if !u.other {
+		newRS.Key = ast.NewIdent("_")
+	}
+	if edits := f.headerEdits(&newRS, rs, rs.Body.Lbrace); edits != nil {
+		for _, e := range u.elems {
+			edits = append(edits, f.edit(e.Pos(), e.End(), v))
+		}
+		f.setFix(p, edits)
+	}
+}
+
+// lintCountingLoop examines loops of the form "for i := 0; i < len(s); i++".
+// It complains if the loop does not modify i or s, since it could be
+// written as a range loop over s.
+func (f *file) lintCountingLoop(fs *ast.ForStmt) {
+	if f.pkg.typesInfo == nil {
+		return
+	}
+	init, ok := fs.Init.(*ast.AssignStmt)
+	if !ok || init.Tok != token.DEFINE || len(init.Lhs) != 1 || len(init.Rhs) != 1 {
+		return
+	}
+	key, ok := init.Lhs[0].(*ast.Ident)
+	if !ok || !isZero(init.Rhs[0]) {
+		return
+	}
+	cond, ok := fs.Cond.(*ast.BinaryExpr)
+	if !ok || cond.Op != token.LSS || !isIdent(cond.X, key.Name) {
+		return
+	}
+	ln, ok := cond.Y.(*ast.CallExpr)
+	if !ok || !isIdent(ln.Fun, "len") || len(ln.Args) != 1 || rootIdent(ln.Args[0]) == nil {
+		return
+	}
+	post, ok := fs.Post.(*ast.IncDecStmt)
+	if !ok || post.Tok != token.INC || !isIdent(post.X, key.Name) {
+		return
+	}
+	x := ln.Args[0]
+	typ := f.pkg.typeOf(x)
+	if typ == nil {
+		return
+	}
+	if p, ok := typ.Underlying().(*types.Pointer); ok {
+		typ = p.Elem()
+	}
+	switch typ.Underlying().(type) {
+	case *types.Slice, *types.Array:
+	default:
+		return
+	}
+	idx := f.pkg.typesInfo.Defs[key]
+	if idx == nil || f.pkg.typesInfo.Uses[cond.X.(*ast.Ident)] != idx {
+		return
+	}
+	u, ok := f.indexUses(fs.Body, idx, x)
+	if !ok {
+		return
+	}
+
+	rs := &ast.RangeStmt{
+		For:  fs.For,
+		Key:  key,
+		Tok:  token.DEFINE,
+		X:    x,
+		Body: fs.Body,
+	}
+	var v string
+	if u.readOnly && len(u.elems) > 0 {
+		v = f.valueName(fs, fs.Body)
+	}
+	switch {
+	case v != "":
+		rs.Value = ast.NewIdent(v)
+		if !u.other {
+			rs.Key = ast.NewIdent("_")
+		}
+	case len(u.elems) == 0 && !u.other:
+		rs.Key, rs.Tok = nil, token.ILLEGAL
+	}
+	header := strings.TrimSuffix(strings.TrimSpace(f.firstLineOf(rs, fs)), " {")
+	if f.callsMayReach(fs.Body, x) {
+		// A range evaluates x once, so it would miss elements appended
+		// during the loop, as in a worklist.
+		f.errorf(fs, 0.4, category("range-loop"), "should replace this loop with %s if %s does not grow during the loop", header, f.render(x))
+		return
+	}
+	p := f.errorf(fs, 0.8, category("range-loop"), "should replace this loop with %s", header)
+	if edits := f.headerEdits(rs, fs, fs.Body.Lbrace); edits != nil {
+		if v != "" {
+			for _, e := range u.elems {
+				edits = append(edits, f.edit(e.Pos(), e.End(), v))
+			}
+		}
+		f.setFix(p, edits)
+	}
+}
+
+// callsMayReach reports whether body makes a call that may modify x, the operand
+// of a counting loop: a method call on the root variable of x or a call that is
+// passed that variable, or any call if x is a package-level variable or is reached
+// through a pointer. Calls of builtin functions and conversions are ignored.
+func (f *file) callsMayReach(body *ast.BlockStmt, x ast.Expr) bool {
+	root := rootIdent(x)
+	obj := f.pkg.typesInfo.ObjectOf(root)
+	if obj == nil {
+		return true
+	}
+	anyCall := obj.Parent() == f.pkg.typesPkg.Scope()
+	for e := x; !anyCall; {
+		if _, ok := f.pkg.typeOf(e).(*types.Pointer); ok && e != x {
+			anyCall = true
+		}
+		sel, ok := e.(*ast.SelectorExpr)
+		if !ok {
+			break
+		}
+		e = sel.X
+	}
+	if _, ok := obj.Type().Underlying().(*types.Pointer); ok {
+		anyCall = true
+	}
+	refersToRoot := func(e ast.Expr) bool {
+		if u, ok := e.(*ast.UnaryExpr); ok && u.Op == token.AND {
+			e = u.X
+		}
+		id := rootIdent(unparen(e))
+		return id != nil && f.pkg.typesInfo.ObjectOf(id) == obj
+	}
+	reaches := false
+	ast.Walk(walker(func(n ast.Node) bool {
+		ce, ok := n.(*ast.CallExpr)
+		if !ok {
+			return !reaches
+		}
+		if tv, ok := f.pkg.typesInfo.Types[ce.Fun]; ok && tv.IsType() {
+			return true
+		}
+		if id, ok := unparen(ce.Fun).(*ast.Ident); ok {
+			if _, ok := f.pkg.typesInfo.Uses[id].(*types.Builtin); ok {
+				return true
+			}
+		}
+		if anyCall {
+			reaches = true
+		}
+		if sel, ok := unparen(ce.Fun).(*ast.SelectorExpr); ok && refersToRoot(sel.X) {
+			reaches = true
+		}
+		for _, arg := range ce.Args {
+			if refersToRoot(arg) {
+				reaches = true
+			}
+		}
+		return !reaches
+	}), body)
+	return reaches
+}
+
+// indexUses describes how the body of a loop over x uses its index.
+type indexUses struct {
+	elems    []*ast.IndexExpr // the expressions x[i]
+	other    bool             // whether i is used other than in elems
+	readOnly bool             // whether the elements of x are only read
+}
+
+// indexUses examines how body uses the index variable idx of a loop over x.
+// It reports false if body may assign to idx or to x itself.
+func (f *file) indexUses(body *ast.BlockStmt, idx types.Object, x ast.Expr) (indexUses, bool) {
+	written := make(map[ast.Expr]bool)  // expressions that are assigned to or addressed
+	mutable := make(map[ast.Expr]bool)  // expressions that may be modified in place
+	harmless := make(map[ast.Expr]bool) // uses of x that cannot modify its elements
+	ast.Walk(walker(func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			for _, e := range n.Lhs {
+				if n.Tok != token.DEFINE || f.pkg.typesInfo.Defs[unparen(e).(*ast.Ident)] == nil {
+					written[unparen(e)] = true
+				}
+			}
+		case *ast.IncDecStmt:
+			written[unparen(n.X)] = true
+		case *ast.UnaryExpr:
+			if n.Op == token.AND {
+				written[unparen(n.X)] = true
+			}
+		case *ast.RangeStmt:
+			harmless[unparen(n.X)] = true
+			if n.Tok == token.ASSIGN {
+				for _, e := range []ast.Expr{n.Key, n.Value} {
+					if e != nil {
+						written[unparen(e)] = true
+					}
+				}
+			}
+		case *ast.SliceExpr:
+			// Slicing an array element takes its address.
+			mutable[unparen(n.X)] = true
+		case *ast.SelectorExpr:
+			if _, ok := f.pkg.typesInfo.Uses[n.Sel].(*types.Func); ok {
+				// A method may have a pointer receiver.
+				mutable[unparen(n.X)] = true
+			}
+		case *ast.IndexExpr:
+			harmless[unparen(n.X)] = true
+		case *ast.CallExpr:
+			if (isIdent(n.Fun, "len") || isIdent(n.Fun, "cap")) && len(n.Args) == 1 {
+				harmless[unparen(n.Args[0])] = true
+			}
+		}
+		return true
+	}), body)
+
+	u := indexUses{readOnly: true}
+	for w := range written {
+		if id, ok := w.(*ast.Ident); ok && f.pkg.typesInfo.ObjectOf(id) == idx {
+			return u, false
+		}
+		for e := x; e != nil; {
+			if f.sameExpr(e, w) {
+				return u, false
+			}
+			sel, ok := e.(*ast.SelectorExpr)
+			if !ok {
+				break
+			}
+			e = sel.X
+		}
+	walk:
+		for e := w; ; {
+			switch v := e.(type) {
+			case *ast.IndexExpr:
+				if f.sameExpr(v.X, x) {
+					u.readOnly = false
+				}
+				e = v.X
+			case *ast.SelectorExpr:
+				e = v.X
+			case *ast.StarExpr:
+				e = v.X
+			case *ast.ParenExpr:
+				e = v.X
+			default:
+				break walk
+			}
+		}
+	}
+
+	ast.Walk(walker(func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.IndexExpr:
+			if id, ok := n.Index.(*ast.Ident); ok && f.pkg.typesInfo.ObjectOf(id) == idx && f.sameExpr(n.X, x) {
+				u.elems = append(u.elems, n)
+				if mutable[n] {
+					u.readOnly = false
+				}
+				return false
+			}
+		case *ast.Ident:
+			if f.pkg.typesInfo.ObjectOf(n) == idx {
+				u.other = true
+			}
+		}
+		switch n := n.(type) {
+		case *ast.Ident, *ast.SelectorExpr:
+			if e := n.(ast.Expr); !harmless[e] && f.sameExpr(e, x) {
+				// x may be modified through this use, such as a function argument.
+				u.readOnly = false
+			}
+		}
+		return true
+	}), body)
+	return u, true
+}
+
+// sameExpr reports whether a and b are the same side-effect free
+// expression referring to the same variable.
+func (f *file) sameExpr(a, b ast.Expr) bool {
+	ra, rb := rootIdent(a), rootIdent(b)
+	if ra == nil || rb == nil || ra.Name != rb.Name {
+		return false
+	}
+	if f.pkg.typesInfo.ObjectOf(ra) != f.pkg.typesInfo.ObjectOf(rb) {
+		return false
+	}
+	return f.render(a) == f.render(b)
+}
+
+// valueName returns a name for the value variable of a range loop
+// replacing loop, or the empty string if no suitable name is free.
+func (f *file) valueName(loop ast.Node, body *ast.BlockStmt) string {
+	const name = "v"
+	for scope := f.pkg.typesInfo.Scopes[loop]; scope != nil; scope = scope.Parent() {
+		obj := scope.Lookup(name)
+		if obj == nil {
+			continue
+		}
+		// Local variables are only in scope after their declaration.
+		if obj.Parent() == types.Universe || obj.Parent() == f.pkg.typesPkg.Scope() || obj.Pos() < loop.Pos() {
+			return ""
+		}
+	}
+	used := false
+	ast.Walk(walker(func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && id.Name == name {
+			used = true
+		}
+		return !used
+	}), body)
+	if used {
+		return ""
+	}
+	return name
+}
+
+// headerEdits returns an edit replacing the header of the statement old,
+// up to and including the opening brace at lbrace, with the first line of node.
+// It returns nil if the header spans more than one line.
+func (f *file) headerEdits(node, old ast.Node, lbrace token.Pos) []Edit {
+	if f.fset.Position(old.Pos()).Line != f.fset.Position(lbrace).Line {
+		return nil
+	}
+	line := strings.TrimPrefix(f.firstLineOf(node, old), f.indentOf(old))
+	return []Edit{f.edit(old.Pos(), lbrace+1, line)}
+}
+
+// lintErrorf examines calls whose only argument is an fmt.Sprintf invocation.
+// It complains if the called function has a formatting variant that could be used instead:
+// fmt.Errorf for errors.New, and the function or method with an "f" suffix otherwise,
+// such as t.Errorf for t.Error or log.Printf for log.Print.
+// It also complains about panic(fmt.Sprintf(...)), which may panic with fmt.Errorf(...).
+func (f *file) lintErrorf() {
+	f.walk(func(node ast.Node) bool {
+		outer, ok := node.(*ast.CallExpr)
+		if !ok || len(outer.Args) != 1 || outer.Ellipsis.IsValid() {
+			return true
+		}
+		inner, ok := outer.Args[0].(*ast.CallExpr)
+		if !ok || !f.isPkgFunc(inner.Fun, "fmt", "Sprintf") || inner.Ellipsis.IsValid() {
+			return true
+		}
+		if id, ok := outer.Fun.(*ast.Ident); ok && id.Name == "panic" && f.isPanic(outer) {
+			p := f.errorf(node, 0.5, category("errors"), "should replace panic(fmt.Sprintf(...)) with panic(fmt.Errorf(...)), unless a recover depends on the value being a string")
+			p.ReplacementLine = f.replaceInLine(inner.Fun, "fmt.Errorf")
+			return true
+		}
+
+		fun := f.formatVariant(outer.Fun)
+		if fun == "" {
+			return true
+		}
+		p := f.errorf(node, 1, category("errors"), "should replace %s(fmt.Sprintf(...)) with %s(...)", f.render(outer.Fun), fun)
+		f.setFix(p, []Edit{
+			f.edit(outer.Pos(), inner.Lparen+1, fun+"("),
+			f.edit(inner.Rparen, outer.Rparen+1, ")"),
+		})
+		return true
+	})
+}
+
+// formatVariant returns the source for the formatting variant of the function fun,
+// or the empty string if it doesn't have one.
+// The variant of errors.New is fmt.Errorf. Otherwise, it is the function
+// or method of the same package or type with an "f" suffix,
+// taking a format string and variadic arguments.
+func (f *file) formatVariant(fun ast.Expr) string {
+	if f.isPkgFunc(fun, "errors", "New") {
+		return "fmt.Errorf"
+	}
+	var id *ast.Ident
+	var x ast.Expr // receiver or package, if any
+	switch v := fun.(type) {
+	case *ast.Ident:
+		id = v
+	case *ast.SelectorExpr:
+		id, x = v.Sel, v.X
+	default:
+		return ""
+	}
+	fn, ok := f.pkg.typesInfo.Uses[id].(*types.Func)
+	if !ok {
+		return ""
+	}
+	name := id.Name + "f"
+	var variant types.Object
+	if fn.Type().(*types.Signature).Recv() != nil {
+		typ := f.pkg.typeOf(x)
+		if typ == nil {
+			return ""
+		}
+		variant, _, _ = types.LookupFieldOrMethod(typ, true, f.pkg.typesPkg, name)
+	} else if fn.Pkg() != nil {
+		variant = fn.Pkg().Scope().Lookup(name)
+	}
+	vf, ok := variant.(*types.Func)
+	if !ok || (!vf.Exported() && vf.Pkg() != f.pkg.typesPkg) {
+		return ""
+	}
+	sig := vf.Type().(*types.Signature)
+	if !sig.Variadic() || sig.Params().Len() != 2 || !types.Identical(sig.Params().At(0).Type(), types.Typ[types.String]) {
+		return ""
+	}
+	if x == nil {
+		return name
+	}
+	return f.render(x) + "." + name
+}
+
+// isPkgFunc reports whether expr refers to the function name of the package with the import path.
+func (f *file) isPkgFunc(expr ast.Expr, path, name string) bool {
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != name {
+		return false
+	}
+	id, ok := sel.X.(*ast.Ident)
+	return ok && f.importPath(id) == path
+}
+
+// isTestingValue reports whether expr is a *testing.T, a *testing.B or a testing.TB.
+func (f *file) isTestingValue(expr ast.Expr) bool {
+	typ := f.pkg.typeOf(expr)
+	if p, ok := typ.(*types.Pointer); ok {
+		typ = p.Elem()
+	}
+	return f.pkg.isNamedType(typ, "testing", "T") || f.pkg.isNamedType(typ, "testing", "B") || f.pkg.isNamedType(typ, "testing", "TB")
+}
+
+// lintErrors examines global error vars. It complains if they aren't named in the standard way.
+func (f *file) lintErrors() {
+	for _, decl := range f.f.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.VAR {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			spec := spec.(*ast.ValueSpec)
+			if len(spec.Names) != 1 || len(spec.Values) != 1 {
+				continue
+			}
+			ce, ok := spec.Values[0].(*ast.CallExpr)
+			if !ok {
+				continue
+			}
+			if !isPkgDot(ce.Fun, "errors", "New") && !isPkgDot(ce.Fun, "fmt", "Errorf") {
+				continue
+			}
+
+			id := spec.Names[0]
+			prefix := "err"
+			if id.IsExported() {
+				prefix = "Err"
+			}
+			if !strings.HasPrefix(id.Name, prefix) {
+				f.errorf(id, 0.9, category("naming"), "error var %s should have name of the form %sFoo", id.Name, prefix)
+			}
+		}
+	}
+}
+
+func lintCapAndPunct(s string) (isCap, isPunct bool) {
+	first, firstN := utf8.DecodeRuneInString(s)
+	last, _ := utf8.DecodeLastRuneInString(s)
+	isPunct = last == '.' || last == ':' || last == '!'
+	isCap = unicode.IsUpper(first)
+	if isCap && len(s) > firstN {
+		// Don't flag strings starting with something that looks like an initialism.
+		if second, _ := utf8.DecodeRuneInString(s[firstN:]); unicode.IsUpper(second) {
+			isCap = false
+		}
+	}
+	return
+}
+
+// errorStringFuncs maps functions that create an error from a string,
+// given by import path and name, to the index of the string in their arguments.
+var errorStringFuncs = map[string]int{
+	"errors.New":                   0,
+	"fmt.Errorf":                   0,
+	"github.com/pkg/errors.New":    0,
+	"github.com/pkg/errors.Errorf": 0,
+	"github.com/pkg/errors.Wrap":   1,
+	"github.com/pkg/errors.Wrapf":  1,
+}
+
+// lintErrorStrings examines error strings. It complains if they are capitalized or end in punctuation.
+// Error strings are the string arguments of the functions in errorStringFuncs
+// and Linter.ErrorFuncs, and of any method named Errorf that returns an error, such as a logger's.
+// The messages of t.Errorf are not error strings, and are left to lintTestMessages.
+// Constant expressions are checked as well as literals.
+func (f *file) lintErrorStrings() {
+	f.walk(func(node ast.Node) bool {
+		ce, ok := node.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		i := f.errorStringArg(ce)
+		if i < 0 || i >= len(ce.Args) {
+			return true
+		}
+		arg := ce.Args[i]
+		var s string
+		if str, ok := arg.(*ast.BasicLit); ok && str.Kind == token.STRING {
+			s, _ = strconv.Unquote(str.Value) // can assume well-formed Go
+		} else if tv, ok := f.pkg.typesInfo.Types[arg]; ok && tv.Value != nil && tv.Value.Kind() == exact.String {
+			s = exact.StringVal(tv.Value)
+		} else {
+			return true
+		}
+		if s == "" {
+			return true
+		}
+		isCap, isPunct := lintCapAndPunct(s)
+		if isCap && f.isProperName(firstWord(s)) {
+			isCap = false
+		}
+		var msg string
+		switch {
+		case isCap && isPunct:
+			msg = "error strings should not be capitalized and should not end with punctuation"
+		case isCap:
+			msg = "error strings should not be capitalized"
+		case isPunct:
+			msg = "error strings should not end with punctuation"
+		default:
+			return true
+		}
+		f.errorf(arg, 0.8, link(styleGuideBase+"#error-strings"), category("errors"), msg)
+		return true
+	})
+}
+
+// firstWord returns the leading run of letters, digits and underscores in s.
+func firstWord(s string) string {
+	for i, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+			return s[:i]
+		}
+	}
+	return s
+}
+
+// isProperName reports whether word may legitimately be capitalized:
+// it is an exported identifier of the package or of a package imported by the file,
+// or it is one of Linter.ProperNouns.
+func (f *file) isProperName(word string) bool {
+	for _, noun := range f.pkg.linter.ProperNouns {
+		if word == noun {
+			return true
+		}
+	}
+	if !ast.IsExported(word) || f.pkg.typesPkg == nil {
+		return false
+	}
+	if f.pkg.typesPkg.Scope().Lookup(word) != nil {
+		return true
+	}
+	for _, is := range f.f.Imports {
+		pkg := f.importedPkg(is)
+		if pkg == nil {
+			continue
+		}
+		if obj := pkg.Scope().Lookup(word); obj != nil && obj.Exported() {
+			return true
+		}
+	}
+	return false
+}
+
+// errorStringArg returns the index of the argument of ce that is an error string,
+// or -1 if ce isn't a call that creates an error.
+func (f *file) errorStringArg(ce *ast.CallExpr) int {
+	sel, ok := ce.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return -1
+	}
+	if id, ok := sel.X.(*ast.Ident); ok {
+		if path := f.importPath(id); path != "" {
+			name := path + "." + sel.Sel.Name
+			if i, ok := errorStringFuncs[name]; ok {
+				return i
+			}
+			for _, fn := range f.pkg.linter.ErrorFuncs {
+				if fn == name {
+					return 0
+				}
+			}
+			return -1
+		}
+	}
+	if sel.Sel.Name != "Errorf" {
+		return -1
+	}
+	// A method such as a logger's Errorf that returns an error.
+	fn, ok := f.pkg.typesInfo.Uses[sel.Sel].(*types.Func)
+	if !ok {
+		return -1
+	}
+	res := fn.Type().(*types.Signature).Results()
+	if res.Len() != 1 || !types.Identical(res.At(0).Type(), errorType) {
+		return -1
+	}
+	return 0
+}
+
+// importPath returns the import path of the package that id refers to,
+// or the empty string if it doesn't refer to an imported package.
+func (f *file) importPath(id *ast.Ident) string {
+	switch obj := f.pkg.typesInfo.Uses[id].(type) {
+	case *types.PkgName:
+		return obj.Imported().Path()
+	case nil:
+		// Type checking failed; look for an import with a matching name.
+		for _, is := range f.f.Imports {
+			name := f.importedName(is)
+			if is.Name != nil {
+				name = is.Name.Name
+			}
+			if name == id.Name {
+				path, _ := strconv.Unquote(is.Path.Value)
+				return path
+			}
+		}
+	}
+	return ""
+}
+
+var badReceiverNames = map[string]bool{
+	"me":   true,
+	"this": true,
+	"self": true,
+}
+
+// lintReceiverNames examines receiver names. It complains about inconsistent
+// names used for the same type and names such as "this".
+func (f *file) lintReceiverNames() {
+	typeReceiver := map[string]string{}
+	f.walk(func(n ast.Node) bool {
+		fn, ok := n.(*ast.FuncDecl)
+		if !ok || fn.Recv == nil || len(fn.Recv.List) == 0 {
+			return true
+		}
+		names := fn.Recv.List[0].Names
+		if len(names) < 1 {
+			return true
+		}
+		name := names[0].Name
+		const ref = styleGuideBase + "#receiver-names"
+		if name == "_" {
+			f.errorf(n, 1, link(ref), category("naming"), `receiver name should not be an underscore`)
+			return true
+		}
+		if badReceiverNames[name] {
+			f.errorf(n, 1, link(ref), category("naming"), `receiver name should be a reflection of its identity; don't use generic names such as "me", "this", or "self"`)
+			return true
+		}
+		recv := receiverType(fn)
+		if prev, ok := typeReceiver[recv]; ok && prev != name {
+			f.errorf(n, 1, link(ref), category("naming"), "receiver name %s should be consistent with previous receiver name %s for %s", name, prev, recv)
+			return true
+		}
+		typeReceiver[recv] = name
+		return true
+	})
+}
+
+// lintIncDec examines statements that increment or decrement a variable.
+// It complains if they don't use x++ or x--.
+func (f *file) lintIncDec() {
+	f.walk(func(n ast.Node) bool {
+		as, ok := n.(*ast.AssignStmt)
+		if !ok {
+			return true
+		}
+		if len(as.Lhs) != 1 {
+			return true
+		}
+		if !f.isOneValue(as.Rhs[0]) {
+			return true
+		}
+		var suffix string
+		switch as.Tok {
+		case token.ADD_ASSIGN:
+			suffix = "++"
+		case token.SUB_ASSIGN:
+			suffix = "--"
+		default:
+			return true
+		}
+		if typ := f.pkg.typeOf(as.Lhs[0]); typ != nil {
+			if b, ok := typ.Underlying().(*types.Basic); ok && b.Info()&(types.IsFloat|types.IsComplex) != 0 {
+				// x++ is legal for floats, but surprising.
+				return true
+			}
+		}
+		repl := f.render(as.Lhs[0]) + suffix
+		p := f.errorf(as, 0.8, category("unary-op"), "should replace %s with %s", f.render(as), repl)
+		f.setFix(p, []Edit{f.edit(as.Pos(), as.End(), repl)})
+		return true
+	})
+}
+
+// isOneValue reports whether expr is the literal 1
+// or a constant expression whose value is 1.
+func (f *file) isOneValue(expr ast.Expr) bool {
+	if isOne(expr) {
+		return true
+	}
+	if f.pkg.typesInfo == nil {
+		return false
+	}
+	tv, ok := f.pkg.typesInfo.Types[expr]
+	if !ok || tv.Value == nil {
+		return false
+	}
+	switch tv.Value.Kind() {
+	case exact.Int, exact.Float:
+		return exact.Compare(tv.Value, token.EQL, exact.MakeInt64(1))
+	}
+	return false
+}
+
+// lintStringConcatInLoops examines string concatenation in loops.
+// It complains about s += x statements in the body of a for or range loop
+// where s is a string declared outside the innermost such loop, since each iteration copies s.
+func (f *file) lintStringConcatInLoops() {
+	f.walk(func(n ast.Node) bool {
+		var loop ast.Node
+		var body *ast.BlockStmt
+		switch v := n.(type) {
+		case *ast.ForStmt:
+			loop, body = v, v.Body
+		case *ast.RangeStmt:
+			loop, body = v, v.Body
+		default:
+			return true
+		}
+		ast.Walk(walker(func(n ast.Node) bool {
+			switch v := n.(type) {
+			case *ast.FuncLit:
+				// Not run once per iteration.
+				return false
+			case *ast.ForStmt, *ast.RangeStmt:
+				// Judged against the innermost loop when it is visited.
+				return false
+			case *ast.AssignStmt:
+				if v.Tok != token.ADD_ASSIGN || len(v.Lhs) != 1 {
+					return true
+				}
+				typ := f.pkg.typeOf(v.Lhs[0])
+				if typ == nil {
+					return true
+				}
+				if b, ok := typ.Underlying().(*types.Basic); !ok || b.Info()&types.IsString == 0 {
+					return true
+				}
+				if id, ok := v.Lhs[0].(*ast.Ident); ok {
+					if obj := f.pkg.typesInfo.ObjectOf(id); obj != nil && loop.Pos() <= obj.Pos() && obj.Pos() < loop.End() {
+						// Declared afresh in each iteration.
+						return true
+					}
+				}
+				f.errorf(v, 0.7, category("performance"), "string %s is built with += in a loop; consider using a strings.Builder or bytes.Buffer", f.render(v.Lhs[0]))
+			}
+			return true
+		}), body)
+		return true
+	})
+}
+
+// lintMake examines statements that declare and initialize a variable with make.
+// It complains if they are constructing a zero element slice,
+// if they pass a redundant zero capacity, or if the slice is
+// immediately filled by appending inside a range of known length.
+// Assignments to existing variables and package-level declarations
+// are not examined, since nil and empty slices can be told apart.
+func (f *file) lintMake() {
+	// Each call is reported at most once, since the suggestions conflict.
+	reported := make(map[*ast.CallExpr]bool)
+	// The init statements of if, for and switch statements cannot be var declarations.
+	inits := make(map[ast.Stmt]bool)
+	pkgSpecs := make(map[ast.Spec]bool)
+	for _, decl := range f.f.Decls {
+		if gd, ok := decl.(*ast.GenDecl); ok {
+			for _, spec := range gd.Specs {
+				pkgSpecs[spec] = true
+			}
+		}
+	}
+	f.walk(func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.IfStmt:
+			inits[n.Init] = true
+		case *ast.ForStmt:
+			inits[n.Init] = true
+		case *ast.SwitchStmt:
+			inits[n.Init] = true
+		case *ast.TypeSwitchStmt:
+			inits[n.Init] = true
+		case *ast.BlockStmt:
+			f.lintMakeCapHints(n.List, reported)
+		case *ast.CaseClause:
+			f.lintMakeCapHints(n.Body, reported)
+		case *ast.CommClause:
+			f.lintMakeCapHints(n.Body, reported)
+		case *ast.AssignStmt:
+			if len(n.Lhs) != 1 || len(n.Rhs) != 1 || n.Tok != token.DEFINE || inits[n] {
+				return true
+			}
+			ce, at := emptyMake(n.Rhs[0])
+			if at == nil || reported[ce] {
+				return true
+			}
+			reported[ce] = true
+			repl := fmt.Sprintf("var %s %s", f.render(n.Lhs[0]), f.render(at))
+			p := f.errorf(n, 0.8, category("slice"), `can probably use "%s" instead`, repl)
+			f.setFix(p, []Edit{f.edit(n.Pos(), n.End(), repl)})
+		case *ast.ValueSpec:
+			if len(n.Names) != 1 || len(n.Values) != 1 || pkgSpecs[n] {
+				return true
+			}
+			ce, at := emptyMake(n.Values[0])
+			if at == nil || reported[ce] {
+				return true
+			}
+			if n.Type != nil {
+				typ := f.pkg.typeOf(n.Type)
+				if typ == nil || !types.Identical(typ, f.pkg.typeOf(ce)) {
+					return true
+				}
+			}
+			reported[ce] = true
+			p := f.errorf(n, 0.8, category("slice"), `can probably use "var %s %s" instead`, n.Names[0].Name, f.render(at))
+			f.setFix(p, []Edit{f.edit(n.Names[0].End(), n.End(), " "+f.render(at))})
+		case *ast.CallExpr:
+			if !isIdent(n.Fun, "make") || len(n.Args) != 3 || reported[n] {
+				return true
+			}
+			if !isZero(n.Args[1]) || !isZero(n.Args[2]) {
+				return true
+			}
+			p := f.errorf(n, 0.9, category("slice"), "should omit zero capacity argument to make")
+			f.setFix(p, []Edit{f.edit(n.Args[1].End(), n.Args[2].End(), "")})
+		}
+		return true
+	})
+}
+
+// lintMakeCapHints examines a statement list for a zero element slice
+// that is appended to on every iteration of the range statement
+// immediately following it, and suggests a capacity for it.
+func (f *file) lintMakeCapHints(stmts []ast.Stmt, reported map[*ast.CallExpr]bool) {
+	if f.pkg.typesInfo == nil {
+		return
+	}
+	for i := 0; i+1 < len(stmts); i++ {
+		id, ce := makeTarget(stmts[i])
+		if id == nil {
+			continue
+		}
+		rs, ok := stmts[i+1].(*ast.RangeStmt)
+		if !ok || !f.hasKnownLen(rs.X) {
+			continue
+		}
+		obj := f.pkg.typesInfo.ObjectOf(id)
+		if obj == nil || !f.appendsTo(rs.Body, obj) {
+			continue
+		}
+		reported[ce] = true
+		n := "len(" + f.render(rs.X) + ")"
+		p := f.errorf(ce, 0.6, category("slice"), "should preallocate %s with make(%s, 0, %s)", id.Name, f.render(ce.Args[0]), n)
+		if len(ce.Args) == 2 {
+			f.setFix(p, []Edit{f.edit(ce.Args[1].End(), ce.Args[1].End(), ", "+n)})
+		} else {
+			f.setFix(p, []Edit{f.edit(ce.Args[2].Pos(), ce.Args[2].End(), n)})
+		}
+	}
+}
+
+// makeTarget returns the variable and call of a statement that
+// initializes or assigns a single variable with a zero element slice.
+func makeTarget(stmt ast.Stmt) (*ast.Ident, *ast.CallExpr) {
+	var lhs, rhs ast.Expr
+	switch stmt := stmt.(type) {
+	case *ast.AssignStmt:
+		if len(stmt.Lhs) != 1 || len(stmt.Rhs) != 1 || (stmt.Tok != token.DEFINE && stmt.Tok != token.ASSIGN) {
+			return nil, nil
+		}
+		lhs, rhs = stmt.Lhs[0], stmt.Rhs[0]
+	case *ast.DeclStmt:
+		gd, ok := stmt.Decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.VAR || len(gd.Specs) != 1 {
+			return nil, nil
+		}
+		vs := gd.Specs[0].(*ast.ValueSpec)
+		if len(vs.Names) != 1 || len(vs.Values) != 1 {
+			return nil, nil
+		}
+		lhs, rhs = vs.Names[0], vs.Values[0]
+	default:
+		return nil, nil
+	}
+	id, ok := lhs.(*ast.Ident)
+	if !ok || id.Name == "_" {
+		return nil, nil
+	}
+	ce, at := emptyMake(rhs)
+	if at == nil {
+		return nil, nil
+	}
+	return id, ce
+}
+
+// emptyMake reports whether expr is make([]T, 0) or make([]T, 0, 0),
+// returning the call and the slice type.
+func emptyMake(expr ast.Expr) (*ast.CallExpr, *ast.ArrayType) {
+	ce, ok := expr.(*ast.CallExpr)
+	if !ok || !isIdent(ce.Fun, "make") || len(ce.Args) < 2 || len(ce.Args) > 3 {
+		return nil, nil
+	}
+	for _, arg := range ce.Args[1:] {
+		if !isZero(arg) {
+			return nil, nil
+		}
+	}
+	at, ok := ce.Args[0].(*ast.ArrayType)
+	if !ok || at.Len != nil {
+		return nil, nil
+	}
+	return ce, at
+}
+
+// hasKnownLen reports whether expr can be evaluated again without side effects
+// and ranging over it yields len(expr) iterations.
+func (f *file) hasKnownLen(expr ast.Expr) bool {
+	if rootIdent(expr) == nil {
+		return false
+	}
+	typ := f.pkg.typeOf(expr)
+	if typ == nil {
+		return false
+	}
+	if p, ok := typ.Underlying().(*types.Pointer); ok {
+		typ = p.Elem()
+	}
+	switch typ.Underlying().(type) {
+	case *types.Slice, *types.Array, *types.Map:
+		return true
+	}
+	return false
+}
+
+// appendsTo reports whether body unconditionally appends a single element
+// to the variable obj, as in "x = append(x, v)".
+func (f *file) appendsTo(body *ast.BlockStmt, obj types.Object) bool {
+	for _, stmt := range body.List {
+		as, ok := stmt.(*ast.AssignStmt)
+		if !ok || as.Tok != token.ASSIGN || len(as.Lhs) != 1 || len(as.Rhs) != 1 {
+			continue
+		}
+		ce, ok := as.Rhs[0].(*ast.CallExpr)
+		if !ok || !isIdent(ce.Fun, "append") || len(ce.Args) != 2 || ce.Ellipsis.IsValid() {
+			continue
+		}
+		lhs, ok1 := as.Lhs[0].(*ast.Ident)
+		arg, ok2 := ce.Args[0].(*ast.Ident)
+		if ok1 && ok2 && f.pkg.typesInfo.ObjectOf(lhs) == obj && f.pkg.typesInfo.ObjectOf(arg) == obj {
+			return true
+		}
+	}
+	return false
+}
+
+// lintErrorReturn examines function declarations that return an error.
+// It complains if the error isn't the last parameter.
+// Any result type that is assignable to error counts as an error.
+func (f *file) lintErrorReturn() {
+	f.walk(func(n ast.Node) bool {
+		fn, ok := n.(*ast.FuncDecl)
+		if !ok || fn.Type.Results == nil {
+			return true
+		}
+		rets := flattenFields(fn.Type.Results)
+		if len(rets) <= 1 {
+			return true
+		}
+		// An error return parameter should be the last parameter.
+		// Flag the first error parameter found before a non-error one.
+		var errs, others []int
+		conf := 0.0
+		for i, r := range rets {
+			c := f.errorConfidence(r.field.Type)
+			if c == 0 {
+				others = append(others, i)
+				continue
+			}
+			if len(errs) == 0 {
+				conf = c
+			}
+			errs = append(errs, i)
 		}
-		if !isIdent(rs.Value, "_") {
-			// for ?, y = range m { ... }
+		if len(errs) == 0 || errs[0] == len(others) {
+			// Every error is already after every non-error.
 			return true
 		}
+		p := f.errorf(fn, conf, category("arg-order"), "error should be the last type when returning multiple items")
+		f.setFix(p, f.errorReturnEdits(fn, rets, append(others, errs...)))
+		return true
+	})
+}
+
+var errorType = types.Universe.Lookup("error").Type()
+
+// errorConfidence returns how confident we are that the type expression expr
+// denotes an error, or 0 if it does not.
+func (f *file) errorConfidence(expr ast.Expr) float64 {
+	typ := f.pkg.typeOf(expr)
+	if typ == nil || typ == types.Typ[types.Invalid] {
+		// Type checking failed, so fall back to the name.
+		if isIdent(expr, "error") {
+			return 0.9
+		}
+		return 0
+	}
+	if types.Identical(typ, errorType) {
+		return 0.9
+	}
+	if types.AssignableTo(typ, errorType) {
+		// A concrete error type or an interface embedding error.
+		return 0.8
+	}
+	return 0
+}
+
+// errorReturnEdits returns the edits that reorder the results of fn,
+// and the values of each of its return statements, as given by order.
+// Callers of fn are not updated.
+// It returns nil if a return statement can't be rewritten,
+// such as one that returns the results of another call.
+func (f *file) errorReturnEdits(fn *ast.FuncDecl, rets []fieldEntry, order []int) []Edit {
+	// Keep names of the same field grouped where they stay adjacent.
+	var parts []string
+	for i := 0; i < len(order); {
+		field := rets[order[i]].field
+		var names []string
+		for ; i < len(order) && rets[order[i]].field == field; i++ {
+			if id := rets[order[i]].name; id != nil {
+				names = append(names, id.Name)
+			}
+		}
+		part := f.srcText(field.Type)
+		if len(names) > 0 {
+			part = strings.Join(names, ", ") + " " + part
+		}
+		parts = append(parts, part)
+	}
+	res := fn.Type.Results
+	edits := []Edit{f.edit(res.Opening, res.Closing+1, "("+strings.Join(parts, ", ")+")")}
 
-		p := f.errorf(rs.Value, 1, category("range-loop"), "should omit 2nd value from range; this loop is equivalent to `for %s %s range ...`", f.render(rs.Key), rs.Tok)
-
-		newRS := *rs // shallow copy
-		newRS.Value = nil
-		p.ReplacementLine = f.firstLineOf(&newRS, rs)
-
+	if fn.Body == nil {
+		return edits
+	}
+	ok := true
+	ast.Walk(walker(func(n ast.Node) bool {
+		switch v := n.(type) {
+		case *ast.FuncLit:
+			// Return statements in here belong to another function.
+			return false
+		case *ast.ReturnStmt:
+			if len(v.Results) == 0 {
+				// Naked return of named results.
+				return false
+			}
+			if len(v.Results) != len(rets) {
+				ok = false
+				return false
+			}
+			vals := make([]string, len(order))
+			for i, j := range order {
+				vals[i] = f.srcText(v.Results[j])
+			}
+			edits = append(edits, f.edit(v.Results[0].Pos(), v.Results[len(v.Results)-1].End(), strings.Join(vals, ", ")))
+			return false
+		}
 		return true
-	})
+	}), fn.Body)
+	if !ok {
+		return nil
+	}
+	return edits
 }
 
-// lintErrorf examines errors.New and testing.Error calls. It complains if its only argument is an fmt.Sprintf invocation.
-func (f *file) lintErrorf() {
-	f.walk(func(node ast.Node) bool {
-		ce, ok := node.(*ast.CallExpr)
-		if !ok || len(ce.Args) != 1 {
+// lintUnexportedReturn examines exported function declarations.
+// It complains if any return an unexported type.
+func (f *file) lintUnexportedReturn() {
+	f.walk(func(n ast.Node) bool {
+		fn, ok := n.(*ast.FuncDecl)
+		if !ok {
 			return true
 		}
-		isErrorsNew := isPkgDot(ce.Fun, "errors", "New")
-		var isTestingError bool
-		se, ok := ce.Fun.(*ast.SelectorExpr)
-		if ok && se.Sel.Name == "Error" {
-			if typ := f.pkg.typeOf(se.X); typ != nil {
-				isTestingError = typ.String() == "*testing.T"
-			}
+		if fn.Type.Results == nil {
+			return false
 		}
-		if !isErrorsNew && !isTestingError {
-			return true
+		if !fn.Name.IsExported() {
+			return false
 		}
-		arg := ce.Args[0]
-		ce, ok = arg.(*ast.CallExpr)
-		if !ok || !isPkgDot(ce.Fun, "fmt", "Sprintf") {
-			return true
+		thing := "func"
+		if fn.Recv != nil && len(fn.Recv.List) > 0 {
+			thing = "method"
+			if !ast.IsExported(receiverType(fn)) {
+				// Don't report exported methods of unexported types,
+				// such as private implementations of sort.Interface.
+				return false
+			}
 		}
-		errorfPrefix := "fmt"
-		if isTestingError {
-			errorfPrefix = f.render(se.X)
+		for _, ret := range fn.Type.Results.List {
+			typ := f.pkg.typeOf(ret.Type)
+			if exportedType(typ) {
+				continue
+			}
+			f.errorf(ret.Type, 0.8, category("unexported-type-in-api"),
+				"exported %s %s returns unexported type %s, which can be annoying to use",
+				thing, fn.Name.Name, typ)
+			break // only flag one
 		}
-		p := f.errorf(node, 1, category("errors"), "should replace %s(fmt.Sprintf(...)) with %s.Errorf(...)", f.render(se), errorfPrefix)
+		return false
+	})
+}
 
-		m := f.srcLineWithMatch(ce, `^(.*)`+f.render(se)+`\(fmt\.Sprintf\((.*)\)\)(.*)$`)
-		if m != nil {
-			p.ReplacementLine = m[1] + errorfPrefix + ".Errorf(" + m[2] + ")" + m[3]
-		}
+// exportedType reports whether typ is an exported type.
+// It is imprecise, and will err on the side of returning true,
+// such as for composite types.
+func exportedType(typ types.Type) bool {
+	switch T := typ.(type) {
+	case *types.Named:
+		// Builtin types have no package.
+		return T.Obj().Pkg() == nil || T.Obj().Exported()
+	case *types.Map:
+		return exportedType(T.Key()) && exportedType(T.Elem())
+	case interface {
+		Elem() types.Type
+	}: // array, slice, pointer, chan
+		return exportedType(T.Elem())
+	}
+	// Be conservative about other types, such as struct, interface, etc.
+	return true
+}
 
-		return true
-	})
+// timeSuffixes is a list of name suffixes that imply a time unit.
+// This is not an exhaustive list; Linter.TimeSuffixes extends it.
+var timeSuffixes = []string{
+	"Nanos", "Ns",
+	"Sec", "Secs", "Seconds",
+	"Msec", "Msecs",
+	"Milli", "Millis", "Milliseconds",
+	"Usec", "Usecs", "Microseconds",
+	"MS", "Ms",
+	"Mins", "Hours", "Days",
 }
 
-// lintErrors examines global error vars. It complains if they aren't named in the standard way.
-func (f *file) lintErrors() {
-	for _, decl := range f.f.Decls {
-		gd, ok := decl.(*ast.GenDecl)
-		if !ok || gd.Tok != token.VAR {
-			continue
+// lintTimeNames examines names of time.Duration variables, struct fields,
+// and function parameters and results.
+// It complains if they have a unit-specific suffix, since a Duration has no unit.
+// It also complains about integer parameters with such a suffix,
+// which might be better as a Duration, and about multiplying two Durations.
+func (f *file) lintTimeNames() {
+	checkList := func(fl *ast.FieldList, thing string, conf float64) {
+		if fl == nil {
+			return
 		}
-		for _, spec := range gd.Specs {
-			spec := spec.(*ast.ValueSpec)
-			if len(spec.Names) != 1 || len(spec.Values) != 1 {
-				continue
+		for _, field := range fl.List {
+			for _, name := range field.Names {
+				f.lintTimeName(name, field, thing, conf)
 			}
-			ce, ok := spec.Values[0].(*ast.CallExpr)
-			if !ok {
-				continue
+		}
+	}
+	f.walk(func(node ast.Node) bool {
+		switch v := node.(type) {
+		case *ast.ValueSpec:
+			for _, name := range v.Names {
+				f.lintTimeName(name, v, "var", 0.9)
 			}
-			if !isPkgDot(ce.Fun, "errors", "New") && !isPkgDot(ce.Fun, "fmt", "Errorf") {
-				continue
+		case *ast.StructType:
+			checkList(v.Fields, "struct field", 0.8)
+		case *ast.FuncType:
+			checkList(v.Params, "func parameter", 0.8)
+			checkList(v.Results, "func result", 0.7)
+			f.lintTimeParams(v.Params)
+		case *ast.BinaryExpr:
+			if v.Op == token.MUL && f.isDurationValue(v.X) && f.isDurationValue(v.Y) {
+				f.errorf(v, 0.8, category("time"), "should not multiply two time.Durations (%s and %s); one of them should be a number of units", f.render(v.X), f.render(v.Y))
 			}
+		}
+		return true
+	})
+}
 
-			id := spec.Names[0]
-			prefix := "err"
-			if id.IsExported() {
-				prefix = "Err"
-			}
-			if !strings.HasPrefix(id.Name, prefix) {
-				f.errorf(id, 0.9, category("naming"), "error var %s should have name of the form %sFoo", id.Name, prefix)
+// lintTimeName complains if name, declared by decl, is a time.Duration
+// or *time.Duration with a unit-specific suffix.
+func (f *file) lintTimeName(name *ast.Ident, decl ast.Node, thing string, conf float64) {
+	origTyp := f.pkg.typeOf(name)
+	// Look for time.Duration or *time.Duration;
+	// the latter is common when using flag.Duration.
+	typ := origTyp
+	if pt, ok := typ.(*types.Pointer); ok {
+		typ = pt.Elem()
+	}
+	if !f.pkg.isNamedType(typ, "time", "Duration") {
+		return
+	}
+	suffix := f.timeSuffix(name.Name)
+	if suffix == "" {
+		return
+	}
+	p := f.errorf(decl, conf, category("time"), "%s %s is of type %v; don't use unit-specific suffix %q", thing, name.Name, origTyp, suffix)
+	if !ast.IsExported(name.Name) {
+		f.setFix(p, f.renameEdits(name, strings.TrimSuffix(name.Name, suffix)))
+	}
+}
+
+// lintTimeParams complains about integer parameters whose name has a unit-specific suffix,
+// since they might be better expressed as a time.Duration.
+func (f *file) lintTimeParams(params *ast.FieldList) {
+	for _, field := range params.List {
+		b, ok := f.pkg.typeOf(field.Type).(*types.Basic)
+		if !ok || b.Info()&types.IsInteger == 0 {
+			continue
+		}
+		for _, name := range field.Names {
+			if suffix := f.timeSuffix(name.Name); suffix != "" {
+				f.errorf(name, 0.6, category("time"), "func parameter %s has type %s and unit-specific suffix %q; consider using a time.Duration", name.Name, b, suffix)
 			}
 		}
 	}
 }
 
-func lintCapAndPunct(s string) (isCap, isPunct bool) {
-	first, firstN := utf8.DecodeRuneInString(s)
-	last, _ := utf8.DecodeLastRuneInString(s)
-	isPunct = last == '.' || last == ':' || last == '!'
-	isCap = unicode.IsUpper(first)
-	if isCap && len(s) > firstN {
-		// Don't flag strings starting with something that looks like an initialism.
-		if second, _ := utf8.DecodeRuneInString(s[firstN:]); unicode.IsUpper(second) {
-			isCap = false
+// timeSuffix returns the suffix of name that implies a time unit,
+// or the empty string if it doesn't have one.
+func (f *file) timeSuffix(name string) string {
+	for _, suffixes := range [][]string{timeSuffixes, f.pkg.linter.TimeSuffixes} {
+		for _, suf := range suffixes {
+			if suf != "" && strings.HasSuffix(name, suf) {
+				return suf
+			}
 		}
 	}
-	return
+	return ""
 }
 
-// lintErrorStrings examines error strings. It complains if they are capitalized or end in punctuation.
-func (f *file) lintErrorStrings() {
-	f.walk(func(node ast.Node) bool {
-		ce, ok := node.(*ast.CallExpr)
-		if !ok {
-			return true
-		}
-		if !isPkgDot(ce.Fun, "errors", "New") && !isPkgDot(ce.Fun, "fmt", "Errorf") {
-			return true
+// renameEdits returns the edits that rename the object declared by id to name.
+// It returns nil if the rename might not be safe: if name isn't a usable identifier,
+// the object is used in another file, or name is already declared in this file
+// or in a scope enclosing id.
+func (f *file) renameEdits(id *ast.Ident, name string) []Edit {
+	obj := f.pkg.typesInfo.Defs[id]
+	if obj == nil || name == "" || name == "_" || token.Lookup(name).IsKeyword() {
+		return nil
+	}
+	for scope := f.pkg.scopeOf(id); scope != nil; scope = scope.Parent() {
+		if scope.Lookup(name) != nil {
+			return nil
 		}
-		if len(ce.Args) < 1 {
-			return true
+	}
+	for _, other := range f.pkg.files {
+		if other == f {
+			continue
 		}
-		str, ok := ce.Args[0].(*ast.BasicLit)
-		if !ok || str.Kind != token.STRING {
-			return true
+		used := false
+		other.walk(func(n ast.Node) bool {
+			if x, ok := n.(*ast.Ident); ok && f.pkg.typesInfo.Uses[x] == obj {
+				used = true
+			}
+			return !used
+		})
+		if used {
+			return nil
 		}
-		s, _ := strconv.Unquote(str.Value) // can assume well-formed Go
-		if s == "" {
+	}
+	var edits []Edit
+	conflict := false
+	f.walk(func(n ast.Node) bool {
+		x, ok := n.(*ast.Ident)
+		if !ok {
 			return true
 		}
-		isCap, isPunct := lintCapAndPunct(s)
-		var msg string
-		switch {
-		case isCap && isPunct:
-			msg = "error strings should not be capitalized and should not end with punctuation"
-		case isCap:
-			msg = "error strings should not be capitalized"
-		case isPunct:
-			msg = "error strings should not end with punctuation"
-		default:
-			return true
+		if x.Name == name && f.pkg.typesInfo.Defs[x] != nil {
+			conflict = true
 		}
-		// People use proper nouns and exported Go identifiers in error strings,
-		// so decrease the confidence of warnings for capitalization.
-		conf := 0.8
-		if isCap {
-			conf = 0.6
+		if f.pkg.typesInfo.ObjectOf(x) == obj {
+			edits = append(edits, f.edit(x.Pos(), x.End(), name))
 		}
-		f.errorf(str, conf, link(styleGuideBase+"#error-strings"), category("errors"), msg)
-		return true
+		return !conflict
 	})
+	if conflict {
+		return nil
+	}
+	return edits
 }
 
-var badReceiverNames = map[string]bool{
-	"me":   true,
-	"this": true,
-	"self": true,
+// isDurationValue reports whether expr is a time.Duration that measures a duration,
+// as opposed to a constant or a conversion, such as the 2 or time.Duration(n)
+// in 2*time.Second or time.Duration(n)*time.Second, that counts units.
+func (f *file) isDurationValue(expr ast.Expr) bool {
+	expr = unparen(expr)
+	if !f.pkg.isNamedType(f.pkg.typeOf(expr), "time", "Duration") {
+		return false
+	}
+	if ce, ok := expr.(*ast.CallExpr); ok {
+		if tv, ok := f.pkg.typesInfo.Types[ce.Fun]; ok && tv.IsType() {
+			return false
+		}
+	}
+	if tv, ok := f.pkg.typesInfo.Types[expr]; ok && tv.Value != nil {
+		if _, untyped := f.isUntypedConst(expr); untyped {
+			return false
+		}
+	}
+	return true
 }
 
-// lintReceiverNames examines receiver names. It complains about inconsistent
-// names used for the same type and names such as "this".
-func (f *file) lintReceiverNames() {
-	typeReceiver := map[string]string{}
+// lintUnusedParams examines function declarations.
+// It complains about named parameters and receivers that are never used in the function body.
+// Stubs, test functions, methods that have the signature of an interface method,
+// and functions that are used as values, such as by assigning them to a variable,
+// often must accept parameters they do not use, so they are not examined.
+func (f *file) lintUnusedParams() {
+	if f.pkg.typesInfo == nil {
+		return
+	}
 	f.walk(func(n ast.Node) bool {
 		fn, ok := n.(*ast.FuncDecl)
-		if !ok || fn.Recv == nil || len(fn.Recv.List) == 0 {
+		if !ok {
 			return true
 		}
-		names := fn.Recv.List[0].Names
-		if len(names) < 1 {
-			return true
+		if fn.Body == nil || f.isStub(fn.Body) || f.isTestFunc(fn) {
+			return false
 		}
-		name := names[0].Name
-		const ref = styleGuideBase + "#receiver-names"
-		if name == "_" {
-			f.errorf(n, 1, link(ref), category("naming"), `receiver name should not be an underscore`)
-			return true
+		obj, ok := f.pkg.typesInfo.Defs[fn.Name].(*types.Func)
+		if !ok || f.pkg.funcValues[obj] {
+			return false
 		}
-		if badReceiverNames[name] {
-			f.errorf(n, 1, link(ref), category("naming"), `receiver name should be a reflection of its identity; don't use generic names such as "me", "this", or "self"`)
+		used := make(map[types.Object]bool)
+		ast.Walk(walker(func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok {
+				if v := f.pkg.typesInfo.Uses[id]; v != nil {
+					used[v] = true
+				}
+			}
 			return true
+		}), fn.Body)
+		unused := func(id *ast.Ident) bool {
+			if id.Name == "_" {
+				return false
+			}
+			v := f.pkg.typesInfo.Defs[id]
+			return v != nil && !used[v]
 		}
-		recv := receiverType(fn)
-		if prev, ok := typeReceiver[recv]; ok && prev != name {
-			f.errorf(n, 1, link(ref), category("naming"), "receiver name %s should be consistent with previous receiver name %s for %s", name, prev, recv)
-			return true
+
+		thing := "func"
+		if fn.Recv != nil && len(fn.Recv.List) > 0 {
+			thing = "method"
+			field := fn.Recv.List[0]
+			if len(field.Names) == 1 && unused(field.Names[0]) {
+				id := field.Names[0]
+				p := f.errorf(id, 0.5, category("unused"), "method %s has unused receiver %s; omit its name", fn.Name.Name, id.Name)
+				f.setFix(p, []Edit{f.edit(id.Pos(), field.Type.Pos(), "")})
+			}
+			if f.pkg.satisfiesInterface(obj) {
+				return false
+			}
 		}
-		typeReceiver[recv] = name
-		return true
+		for _, field := range fn.Type.Params.List {
+			for _, id := range field.Names {
+				if !unused(id) {
+					continue
+				}
+				if fn.Name.IsExported() {
+					// Removing the parameter would change the API.
+					f.errorf(id, 0.6, category("unused"), "exported %s %s has unused parameter %s", thing, fn.Name.Name, id.Name)
+					continue
+				}
+				p := f.errorf(id, 0.8, category("unused"), "%s %s has unused parameter %s; rename it to _ or remove it", thing, fn.Name.Name, id.Name)
+				f.setFix(p, []Edit{f.edit(id.Pos(), id.End(), "_")})
+			}
+		}
+		return false
 	})
 }
 
-// lintIncDec examines statements that increment or decrement a variable.
-// It complains if they don't use x++ or x--.
-func (f *file) lintIncDec() {
-	f.walk(func(n ast.Node) bool {
-		as, ok := n.(*ast.AssignStmt)
-		if !ok {
-			return true
-		}
-		if len(as.Lhs) != 1 {
-			return true
-		}
-		if !isOne(as.Rhs[0]) {
-			return true
-		}
-		var suffix string
-		switch as.Tok {
-		case token.ADD_ASSIGN:
-			suffix = "++"
-		case token.SUB_ASSIGN:
-			suffix = "--"
-		default:
-			return true
+// isStub reports whether body is empty, or only returns constants or panics.
+func (f *file) isStub(body *ast.BlockStmt) bool {
+	if len(body.List) == 0 {
+		return true
+	}
+	if len(body.List) > 1 {
+		return false
+	}
+	switch stmt := body.List[0].(type) {
+	case *ast.ReturnStmt:
+		for _, r := range stmt.Results {
+			if isIdent(r, "nil") {
+				continue
+			}
+			if tv, ok := f.pkg.typesInfo.Types[r]; !ok || tv.Value == nil {
+				return false
+			}
 		}
-		f.errorf(as, 0.8, category("unary-op"), "should replace %s with %s%s", f.render(as), f.render(as.Lhs[0]), suffix)
 		return true
-	})
+	case *ast.ExprStmt:
+		ce, ok := stmt.X.(*ast.CallExpr)
+		return ok && f.isPanic(ce)
+	}
+	return false
 }
 
-// lintMake examines statements that declare and initialize a variable with make.
-// It complains if they are constructing a zero element slice.
-func (f *file) lintMake() {
+// isTestFunc reports whether fn is a test or benchmark function,
+// whose signature is fixed by the testing package.
+func (f *file) isTestFunc(fn *ast.FuncDecl) bool {
+	if !f.isTest() || fn.Recv != nil {
+		return false
+	}
+	name := fn.Name.Name
+	return strings.HasPrefix(name, "Test") && f.hasTestingParam(fn, "T") ||
+		strings.HasPrefix(name, "Benchmark") && f.hasTestingParam(fn, "B")
+}
+
+// lintComplexity examines exported functions and methods.
+// It complains if their cyclomatic complexity or number of statements
+// exceeds the linter's MaxComplexity or MaxStatements.
+func (f *file) lintComplexity() {
+	l := f.pkg.linter
+	if l.MaxComplexity <= 0 && l.MaxStatements <= 0 {
+		return
+	}
 	f.walk(func(n ast.Node) bool {
-		as, ok := n.(*ast.AssignStmt)
+		fn, ok := n.(*ast.FuncDecl)
 		if !ok {
 			return true
 		}
-		// Only want single var := assignment statements.
-		if len(as.Lhs) != 1 || as.Tok != token.DEFINE {
-			return true
-		}
-		ce, ok := as.Rhs[0].(*ast.CallExpr)
+		m, ok := f.funcMetrics(fn)
 		if !ok {
-			return true
+			return false
 		}
-		// Check if ce is make([]T, 0).
-		if !isIdent(ce.Fun, "make") || len(ce.Args) != 2 || !isZero(ce.Args[1]) {
-			return true
+		thing := "func"
+		if fn.Recv != nil {
+			thing = "method"
 		}
-		at, ok := ce.Args[0].(*ast.ArrayType)
-		if !ok || at.Len != nil {
-			return true
+		if l.MaxComplexity > 0 && m.Complexity > l.MaxComplexity {
+			f.errorf(fn.Name, 1, category("complexity"), "exported %s %s has cyclomatic complexity %d, more than %d; split it into smaller functions", thing, m.Name, m.Complexity, l.MaxComplexity)
 		}
-		f.errorf(as, 0.8, category("slice"), `can probably use "var %s %s" instead`, f.render(as.Lhs[0]), f.render(at))
-		return true
+		if l.MaxStatements > 0 && m.Statements > l.MaxStatements {
+			f.errorf(fn.Name, 1, category("complexity"), "exported %s %s has %d statements, more than %d; split it into smaller functions", thing, m.Name, m.Statements, l.MaxStatements)
+		}
+		return false
 	})
 }
 
-// lintErrorReturn examines function declarations that return an error.
-// It complains if the error isn't the last parameter.
-func (f *file) lintErrorReturn() {
-	f.walk(func(n ast.Node) bool {
-		fn, ok := n.(*ast.FuncDecl)
-		if !ok || fn.Type.Results == nil {
-			return true
-		}
-		ret := fn.Type.Results.List
-		if len(ret) <= 1 {
-			return true
+// funcMetrics computes the metrics of fn.
+// It reports false if fn is not an exported function or method with a body.
+// The cyclomatic complexity is one more than the number of decision points:
+// if, for and range statements, non-default cases, and && and || operators.
+// The statements counted are those in blocks and case bodies, so the init
+// and post statements of an if, for or switch and the communication of a
+// select case are not.
+func (f *file) funcMetrics(fn *ast.FuncDecl) (FuncMetrics, bool) {
+	if fn.Body == nil || !fn.Name.IsExported() {
+		return FuncMetrics{}, false
+	}
+	name := fn.Name.Name
+	if fn.Recv != nil && len(fn.Recv.List) > 0 {
+		recv := receiverType(fn)
+		if !ast.IsExported(recv) {
+			return FuncMetrics{}, false
 		}
-		// An error return parameter should be the last parameter.
-		// Flag any error parameters found before the last.
-		for _, r := range ret[:len(ret)-1] {
-			if isIdent(r.Type, "error") {
-				f.errorf(fn, 0.9, category("arg-order"), "error should be the last type when returning multiple items")
-				break // only flag one
+		name = recv + "." + name
+	}
+	m := FuncMetrics{
+		Position:   f.fset.Position(fn.Name.Pos()),
+		Name:       name,
+		Complexity: 1,
+	}
+	ast.Walk(walker(func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.IfStmt, *ast.ForStmt, *ast.RangeStmt:
+			m.Complexity++
+		case *ast.CaseClause:
+			if n.List != nil {
+				m.Complexity++
+			}
+			m.Statements += len(n.Body)
+		case *ast.CommClause:
+			if n.Comm != nil {
+				m.Complexity++
+			}
+			m.Statements += len(n.Body)
+		case *ast.BinaryExpr:
+			if n.Op == token.LAND || n.Op == token.LOR {
+				m.Complexity++
+			}
+		case *ast.BlockStmt:
+			for _, stmt := range n.List {
+				switch stmt.(type) {
+				case *ast.CaseClause, *ast.CommClause:
+					// Counted by their bodies.
+				default:
+					m.Statements++
+				}
 			}
 		}
 		return true
-	})
+	}), fn.Body)
+	return m, true
 }
 
-// lintUnexportedReturn examines exported function declarations.
-// It complains if any return an unexported type.
-func (f *file) lintUnexportedReturn() {
-	f.walk(func(n ast.Node) bool {
-		fn, ok := n.(*ast.FuncDecl)
-		if !ok {
-			return true
-		}
-		if fn.Type.Results == nil {
-			return false
+// lintPanics examines exported functions of library packages.
+// It complains about calls to panic, which shouldn't be used for normal error handling.
+// Panics in init functions, Must* functions and the default case of a switch
+// that covers every declared constant of its type are usually deliberate,
+// so they are reported with lower confidence.
+func (f *file) lintPanics() {
+	if f.pkg.main || f.isTest() {
+		return
+	}
+	for _, decl := range f.f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
 		}
-		if !fn.Name.IsExported() {
-			return false
+		isInit := fn.Recv == nil && fn.Name.Name == "init"
+		if !isInit && !fn.Name.IsExported() {
+			continue
 		}
 		thing := "func"
 		if fn.Recv != nil && len(fn.Recv.List) > 0 {
 			thing = "method"
 			if !ast.IsExported(receiverType(fn)) {
-				// Don't report exported methods of unexported types,
-				// such as private implementations of sort.Interface.
-				return false
+				continue
 			}
 		}
-		for _, ret := range fn.Type.Results.List {
-			typ := f.pkg.typeOf(ret.Type)
-			if exportedType(typ) {
-				continue
+
+		// Find the panics in the default case of exhaustive switches.
+		expected := make(map[*ast.CallExpr]bool)
+		ast.Walk(walker(func(n ast.Node) bool {
+			sw, ok := n.(*ast.SwitchStmt)
+			if !ok {
+				return true
 			}
-			f.errorf(ret.Type, 0.8, category("unexported-type-in-api"),
-				"exported %s %s returns unexported type %s, which can be annoying to use",
-				thing, fn.Name.Name, typ)
-			break // only flag one
+			if def := f.exhaustiveDefault(sw); def != nil {
+				ast.Walk(walker(func(n ast.Node) bool {
+					if ce, ok := n.(*ast.CallExpr); ok && f.isPanic(ce) {
+						expected[ce] = true
+					}
+					return true
+				}), def)
+			}
+			return true
+		}), fn.Body)
+
+		conf := 0.8
+		if isInit || strings.HasPrefix(fn.Name.Name, "Must") {
+			conf = 0.3
 		}
+		ast.Walk(walker(func(n ast.Node) bool {
+			ce, ok := n.(*ast.CallExpr)
+			if !ok || !f.isPanic(ce) {
+				return true
+			}
+			c := conf
+			if expected[ce] {
+				c = 0.3
+			}
+			f.errorf(ce, c, link(styleGuideBase+"#dont-panic"), category("errors"), "%s %s should not use panic for normal error handling; return an error instead", thing, fn.Name.Name)
+			return true
+		}), fn.Body)
+	}
+}
+
+// isPanic reports whether ce is a call of the builtin panic.
+func (f *file) isPanic(ce *ast.CallExpr) bool {
+	id, ok := ce.Fun.(*ast.Ident)
+	if !ok || id.Name != "panic" {
 		return false
-	})
+	}
+	if f.pkg.typesInfo == nil {
+		return true
+	}
+	obj := f.pkg.typesInfo.Uses[id]
+	if obj == nil {
+		// Type checking failed; assume it is the builtin.
+		return true
+	}
+	_, ok = obj.(*types.Builtin)
+	return ok
 }
 
-// exportedType reports whether typ is an exported type.
-// It is imprecise, and will err on the side of returning true,
-// such as for composite types.
-func exportedType(typ types.Type) bool {
-	switch T := typ.(type) {
-	case *types.Named:
-		// Builtin types have no package.
-		return T.Obj().Pkg() == nil || T.Obj().Exported()
-	case *types.Map:
-		return exportedType(T.Key()) && exportedType(T.Elem())
-	case interface {
-		Elem() types.Type
-	}: // array, slice, pointer, chan
-		return exportedType(T.Elem())
+// exhaustiveDefault returns the default clause of sw if the switch has
+// a case for every constant declared with the named type of its tag.
+// It returns nil otherwise.
+func (f *file) exhaustiveDefault(sw *ast.SwitchStmt) *ast.CaseClause {
+	if sw.Tag == nil || f.pkg.typesInfo == nil {
+		return nil
 	}
-	// Be conservative about other types, such as struct, interface, etc.
-	return true
+	var def *ast.CaseClause
+	var covered []exact.Value
+	for _, stmt := range sw.Body.List {
+		cc := stmt.(*ast.CaseClause)
+		if cc.List == nil {
+			def = cc
+			continue
+		}
+		for _, e := range cc.List {
+			if tv, ok := f.pkg.typesInfo.Types[e]; ok && tv.Value != nil {
+				covered = append(covered, tv.Value)
+			}
+		}
+	}
+	if def == nil {
+		return nil
+	}
+	named, ok := f.pkg.typeOf(sw.Tag).(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return nil
+	}
+	scope := named.Obj().Pkg().Scope()
+	consts := 0
+	for _, name := range scope.Names() {
+		c, ok := scope.Lookup(name).(*types.Const)
+		if !ok || !types.Identical(c.Type(), named) {
+			continue
+		}
+		consts++
+		found := false
+		for _, v := range covered {
+			if exact.Compare(c.Val(), token.EQL, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil
+		}
+	}
+	if consts == 0 {
+		return nil
+	}
+	return def
 }
 
-// timeSuffixes is a list of name suffixes that imply a time unit.
-// This is not an exhaustive list.
-var timeSuffixes = []string{
-	"Sec", "Secs", "Seconds",
-	"Msec", "Msecs",
-	"Milli", "Millis", "Milliseconds",
-	"Usec", "Usecs", "Microseconds",
-	"MS", "Ms",
+// lintGoroutineLifetimes examines goroutines started by exported functions.
+// It complains about a function literal run as a goroutine if it is given no way
+// of being stopped or waited for: it neither captures nor is passed
+// a context.Context, a channel or a sync.WaitGroup.
+// This is a heuristic, so it is reported with low confidence.
+func (f *file) lintGoroutineLifetimes() {
+	if f.pkg.main || f.isTest() || f.pkg.typesInfo == nil {
+		return
+	}
+	for _, decl := range f.f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil || !fn.Name.IsExported() {
+			continue
+		}
+		thing := "func"
+		if fn.Recv != nil && len(fn.Recv.List) > 0 {
+			thing = "method"
+			if !ast.IsExported(receiverType(fn)) {
+				continue
+			}
+		}
+		ast.Walk(walker(func(n ast.Node) bool {
+			gs, ok := n.(*ast.GoStmt)
+			if !ok {
+				return true
+			}
+			lit, ok := gs.Call.Fun.(*ast.FuncLit)
+			if !ok {
+				return true
+			}
+			for _, arg := range gs.Call.Args {
+				if f.isLifetimeType(f.pkg.typeOf(arg)) {
+					return true
+				}
+			}
+			if f.capturesLifetime(lit) {
+				return true
+			}
+			f.errorf(gs, 0.3, link(styleGuideBase+"#goroutine-lifetimes"), category("concurrency"), "goroutine started by exported %s %s has no clear lifetime; give it a context.Context, done channel or sync.WaitGroup", thing, fn.Name.Name)
+			return true
+		}), fn.Body)
+	}
 }
 
-func (f *file) lintTimeNames() {
-	f.walk(func(node ast.Node) bool {
-		v, ok := node.(*ast.ValueSpec)
-		if !ok {
+// isLifetimeType reports whether typ is commonly used to bound the lifetime of a goroutine.
+func (f *file) isLifetimeType(typ types.Type) bool {
+	if typ == nil {
+		return false
+	}
+	if pt, ok := typ.(*types.Pointer); ok {
+		typ = pt.Elem()
+	}
+	if _, ok := typ.Underlying().(*types.Chan); ok {
+		return true
+	}
+	return f.pkg.isNamedType(typ, "context", "Context") ||
+		f.pkg.isNamedType(typ, "golang.org/x/net/context", "Context") ||
+		f.pkg.isNamedType(typ, "sync", "WaitGroup")
+}
+
+// capturesLifetime reports whether lit refers to a variable from outside itself,
+// or a field or method reached through one, that has a lifetime type.
+func (f *file) capturesLifetime(lit *ast.FuncLit) bool {
+	found := false
+	ast.Walk(walker(func(n ast.Node) bool {
+		if found {
+			return false
+		}
+		var root *ast.Ident
+		switch v := n.(type) {
+		case *ast.Ident:
+			root = v
+		case *ast.SelectorExpr:
+			root = rootIdent(v)
+		default:
 			return true
 		}
-		for _, name := range v.Names {
-			origTyp := f.pkg.typeOf(name)
-			// Look for time.Duration or *time.Duration;
-			// the latter is common when using flag.Duration.
-			typ := origTyp
-			if pt, ok := typ.(*types.Pointer); ok {
-				typ = pt.Elem()
-			}
-			if !f.pkg.isNamedType(typ, "time", "Duration") {
-				continue
+		if root == nil {
+			return true
+		}
+		obj, ok := f.pkg.typesInfo.Uses[root].(*types.Var)
+		if !ok || (lit.Pos() <= obj.Pos() && obj.Pos() < lit.End()) {
+			return true
+		}
+		if f.isLifetimeType(f.pkg.typeOf(n.(ast.Expr))) {
+			found = true
+		}
+		return true
+	}), lit.Body)
+	return found
+}
+
+// rootIdent returns the identifier at the start of a chain of selectors,
+// such as x in x.y.z, or nil if there isn't one.
+func rootIdent(expr ast.Expr) *ast.Ident {
+	for {
+		switch v := expr.(type) {
+		case *ast.Ident:
+			return v
+		case *ast.SelectorExpr:
+			expr = v.X
+		default:
+			return nil
+		}
+	}
+}
+
+// lintMutexes examines the use of mutexes in types.
+// It complains about exported structs that embed a sync.Mutex or sync.RWMutex,
+// since that makes Lock and Unlock part of the type's API,
+// and about value receivers on types that contain a mutex,
+// since each call then operates on a copy of the lock.
+func (f *file) lintMutexes() {
+	f.walk(func(n ast.Node) bool {
+		switch v := n.(type) {
+		case *ast.TypeSpec:
+			st, ok := v.Type.(*ast.StructType)
+			if !ok || !v.Name.IsExported() {
+				return false
 			}
-			suffix := ""
-			for _, suf := range timeSuffixes {
-				if strings.HasSuffix(name.Name, suf) {
-					suffix = suf
-					break
+			for _, field := range st.Fields.List {
+				if len(field.Names) > 0 {
+					continue
+				}
+				typ := f.pkg.typeOf(field.Type)
+				if pt, ok := typ.(*types.Pointer); ok {
+					typ = pt.Elem()
+				}
+				if name := f.mutexName(typ); name != "" {
+					f.errorf(field, 0.8, category("concurrency"), "exported struct %s embeds %s, which makes Lock and Unlock part of its API; use a named field instead", v.Name.Name, name)
 				}
 			}
-			if suffix == "" {
-				continue
+			return false
+		case *ast.FuncDecl:
+			if v.Recv == nil || len(v.Recv.List) == 0 {
+				return false
+			}
+			if _, ok := v.Recv.List[0].Type.(*ast.StarExpr); ok {
+				return false
 			}
-			f.errorf(v, 0.9, category("time"), "var %s is of type %v; don't use unit-specific suffix %q", name.Name, origTyp, suffix)
+			if name := f.containedMutex(f.pkg.typeOf(v.Recv.List[0].Type)); name != "" {
+				recv := receiverType(v)
+				f.errorf(v.Recv, 0.9, category("concurrency"), "method %s.%s has a value receiver, but %s contains a %s that will be copied; use a pointer receiver", recv, v.Name.Name, recv, name)
+			}
+			return false
 		}
 		return true
 	})
 }
 
+// mutexName returns the qualified name of typ if it is sync.Mutex or sync.RWMutex,
+// or the empty string otherwise.
+func (f *file) mutexName(typ types.Type) string {
+	for _, name := range []string{"Mutex", "RWMutex"} {
+		if f.pkg.isNamedType(typ, "sync", name) {
+			return "sync." + name
+		}
+	}
+	return ""
+}
+
+// containedMutex returns the name of the mutex type that typ holds by value,
+// either directly or in a struct field or array element,
+// or the empty string if there isn't one.
+func (f *file) containedMutex(typ types.Type) string {
+	if typ == nil {
+		return ""
+	}
+	if name := f.mutexName(typ); name != "" {
+		return name
+	}
+	switch t := typ.Underlying().(type) {
+	case *types.Struct:
+		for i := 0; i < t.NumFields(); i++ {
+			if name := f.containedMutex(t.Field(i).Type()); name != "" {
+				return name
+			}
+		}
+	case *types.Array:
+		return f.containedMutex(t.Elem())
+	}
+	return ""
+}
+
 func receiverType(fn *ast.FuncDecl) string {
 	switch e := fn.Recv.List[0].Type.(type) {
 	case *ast.Ident:
@@ -1418,6 +4268,28 @@ func receiverType(fn *ast.FuncDecl) string {
 	panic(fmt.Sprintf("unknown method receiver AST node type %T", fn.Recv.List[0].Type))
 }
 
+// A fieldEntry is a single name of a field list,
+// or a whole field if the field is unnamed.
+type fieldEntry struct {
+	field *ast.Field
+	name  *ast.Ident // nil for an unnamed field
+}
+
+// flattenFields expands fl so that there is one entry per name.
+func flattenFields(fl *ast.FieldList) []fieldEntry {
+	var entries []fieldEntry
+	for _, field := range fl.List {
+		if len(field.Names) == 0 {
+			entries = append(entries, fieldEntry{field: field})
+			continue
+		}
+		for _, id := range field.Names {
+			entries = append(entries, fieldEntry{field: field, name: id})
+		}
+	}
+	return entries
+}
+
 func (f *file) walk(fn func(ast.Node) bool) {
 	ast.Walk(walker(fn), f.f)
 }
@@ -1430,6 +4302,46 @@ func (f *file) render(x interface{}) string {
 	return buf.String()
 }
 
+// srcText returns the source text of node, as written.
+func (f *file) srcText(node ast.Node) string {
+	return f.srcBetween(node.Pos(), node.End())
+}
+
+// srcBetween returns the source text in [pos, end).
+func (f *file) srcBetween(pos, end token.Pos) string {
+	return string(f.src[f.fset.Position(pos).Offset:f.fset.Position(end).Offset])
+}
+
+// edit returns an Edit that replaces the source in [pos, end) with s.
+func (f *file) edit(pos, end token.Pos, s string) Edit {
+	return Edit{Pos: f.fset.Position(pos), End: f.fset.Position(end), New: s}
+}
+
+// setFix attaches the fix made of edits to p. If the edits only change
+// the line of p, it is attached as p's ReplacementLine, and as p's Edits otherwise.
+func (f *file) setFix(p *Problem, edits []Edit) {
+	if len(edits) == 0 {
+		return
+	}
+	for _, e := range edits {
+		if e.Pos.Line != p.Position.Line || e.End.Line != p.Position.Line || strings.Contains(e.New, "\n") {
+			p.Edits = edits
+			return
+		}
+	}
+	line := strings.TrimSuffix(srcLine(f.src, edits[0].Pos), "\n")
+	start := edits[0].Pos.Offset - (edits[0].Pos.Column - 1) // offset of the start of the line
+	var buf bytes.Buffer
+	last := 0
+	for _, e := range edits {
+		buf.WriteString(line[last : e.Pos.Offset-start])
+		buf.WriteString(e.New)
+		last = e.End.Offset - start
+	}
+	buf.WriteString(line[last:])
+	p.ReplacementLine = buf.String()
+}
+
 func (f *file) debugRender(x interface{}) string {
 	var buf bytes.Buffer
 	if err := ast.Fprint(&buf, f.fset, x, nil); err != nil {
@@ -1449,6 +4361,17 @@ func (w walker) Visit(node ast.Node) ast.Visitor {
 	return nil
 }
 
+// unparen returns expr with any enclosing parentheses removed.
+func unparen(expr ast.Expr) ast.Expr {
+	for {
+		pe, ok := expr.(*ast.ParenExpr)
+		if !ok {
+			return expr
+		}
+		expr = pe.X
+	}
+}
+
 func isIdent(expr ast.Expr, ident string) bool {
 	id, ok := expr.(*ast.Ident)
 	return ok && id.Name == ident
@@ -1524,11 +4447,16 @@ func (f *file) indentOf(node ast.Node) string {
 	return line // unusual or empty line
 }
 
-func (f *file) srcLineWithMatch(node ast.Node, pattern string) (m []string) {
-	line := srcLine(f.src, f.fset.Position(node.Pos()))
-	line = strings.TrimSuffix(line, "\n")
-	rx := regexp.MustCompile(pattern)
-	return rx.FindStringSubmatch(line)
+// replaceInLine returns the source line containing node with the text of node replaced by s.
+// It returns the empty string if node spans more than one line.
+func (f *file) replaceInLine(node ast.Node, s string) string {
+	pos, end := f.fset.Position(node.Pos()), f.fset.Position(node.End())
+	if pos.Line != end.Line {
+		return ""
+	}
+	line := strings.TrimSuffix(srcLine(f.src, pos), "\n")
+	start := pos.Offset - (pos.Column - 1) // offset of the start of the line
+	return line[:pos.Offset-start] + s + line[end.Offset-start:]
 }
 
 // srcLine returns the complete line at p, including the terminating newline.
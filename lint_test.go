@@ -16,6 +16,7 @@ import (
 	"go/token"
 	"io/ioutil"
 	"path"
+	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
@@ -51,7 +52,7 @@ func TestAll(t *testing.T) {
 			t.Fatalf("Failed reading %s: %v", fi.Name(), err)
 		}
 
-		ins := parseInstructions(t, fi.Name(), src)
+		ins, ignore := parseInstructions(t, fi.Name(), src)
 		if ins == nil {
 			t.Errorf("Test file %v does not have instructions", fi.Name())
 			continue
@@ -96,11 +97,341 @@ func TestAll(t *testing.T) {
 			}
 		}
 		for _, p := range ps {
+			if ignore[p.Category] {
+				continue
+			}
 			t.Errorf("Unexpected problem at %s:%d: %v", fi.Name(), p.Position.Line, p.Text)
 		}
 	}
 }
 
+func TestErrorStringConfig(t *testing.T) {
+	const src = `package foo
+
+import "example.com/xerr"
+
+func f(x int) error {
+	if x > 0 {
+		return xerr.New("Bad things.")
+	}
+	if x < 0 {
+		return xerr.New("Google is down")
+	}
+	return xerr.Wrap("bad things.")
+}
+`
+	l := &Linter{
+		ErrorFuncs:  []string{"example.com/xerr.New"},
+		ProperNouns: []string{"Google"},
+	}
+	ps, err := l.Lint("foo.go", []byte(src))
+	if err != nil {
+		t.Fatalf("Linting: %v", err)
+	}
+	var lines []int
+	for _, p := range ps {
+		if p.Category == "errors" {
+			lines = append(lines, p.Position.Line)
+		}
+	}
+	if len(lines) != 1 || lines[0] != 7 {
+		t.Errorf("Got error string problems on lines %v, want [7]", lines)
+	}
+}
+
+func TestPanicConfidence(t *testing.T) {
+	const src = `package foo
+
+// Color is a color.
+type Color int
+
+// These are the colors.
+const (
+	Red Color = iota
+	Green
+)
+
+// F panics.
+func F() {
+	panic("F")
+}
+
+// MustF panics.
+func MustF() {
+	panic("MustF")
+}
+
+func init() {
+	panic("init")
+}
+
+// Name panics in the default case of an exhaustive switch.
+func (c Color) Name() string {
+	switch c {
+	case Red:
+		return "red"
+	case Green:
+		return "green"
+	default:
+		panic("Name")
+	}
+}
+`
+	tests := []struct {
+		name string
+		conf float64
+	}{
+		{"F", 0.8},
+		{"MustF", 0.3},
+		{"init", 0.3},
+		{"Name", 0.3},
+	}
+	ps, err := new(Linter).Lint("foo.go", []byte(src))
+	if err != nil {
+		t.Fatalf("Linting: %v", err)
+	}
+	for _, test := range tests {
+		found := false
+		for _, p := range ps {
+			if p.Category != "errors" || !strings.Contains(p.Text, " "+test.name+" should not use panic") {
+				continue
+			}
+			found = true
+			if p.Confidence != test.conf {
+				t.Errorf("Panic in %s: got confidence %v, want %v", test.name, p.Confidence, test.conf)
+			}
+		}
+		if !found {
+			t.Errorf("Panic in %s: not reported", test.name)
+		}
+	}
+}
+
+func TestMaxNesting(t *testing.T) {
+	const src = `package foo
+
+func f(x int) int {
+	if x > 0 {
+		for x > 1 {
+			if x%2 == 0 {
+				x--
+			}
+		}
+	}
+	return x
+}
+`
+	tests := []struct {
+		max   int
+		lines []int
+	}{
+		{0, nil},
+		{3, nil},
+		{2, []int{6}},
+		{1, []int{5}},
+	}
+	for _, test := range tests {
+		l := &Linter{MaxNesting: test.max}
+		ps, err := l.Lint("foo.go", []byte(src))
+		if err != nil {
+			t.Fatalf("Linting: %v", err)
+		}
+		var lines []int
+		for _, p := range ps {
+			if p.Category == "indent" {
+				lines = append(lines, p.Position.Line)
+				if p.Confidence < 0.8 {
+					t.Errorf("MaxNesting %d: got confidence %v at line %d, want at least 0.8", test.max, p.Confidence, p.Position.Line)
+				}
+			}
+		}
+		if !reflect.DeepEqual(lines, test.lines) {
+			t.Errorf("MaxNesting %d: got nesting problems on lines %v, want %v", test.max, lines, test.lines)
+		}
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	const src = `package foo
+
+// T is a type.
+type T int
+
+// F is a function.
+func F(x int, c chan int) int {
+	if x > 0 && x < 10 || x == 20 {
+		x++
+	}
+	for i := 0; i < x; i++ {
+		switch i {
+		case 1, 2:
+			x--
+		default:
+		}
+	}
+	select {
+	case <-c:
+	default:
+	}
+	return x
+}
+
+// M is a method.
+func (T) M() {}
+
+func g() {}
+
+type t int
+
+// M is a method of an unexported type.
+func (t) M() {}
+`
+	l := &Linter{}
+	ms, err := l.Metrics(map[string][]byte{"foo.go": []byte(src)})
+	if err != nil {
+		t.Fatalf("Metrics: %v", err)
+	}
+	want := []FuncMetrics{
+		{Name: "F", Complexity: 7, Statements: 7},
+		{Name: "T.M", Complexity: 1, Statements: 0},
+	}
+	if len(ms) != len(want) {
+		t.Fatalf("Got %d functions, want %d: %+v", len(ms), len(want), ms)
+	}
+	for i, m := range ms {
+		if m.Name != want[i].Name || m.Complexity != want[i].Complexity || m.Statements != want[i].Statements {
+			t.Errorf("Got metrics %s: complexity %d, %d statements; want %s: complexity %d, %d statements",
+				m.Name, m.Complexity, m.Statements, want[i].Name, want[i].Complexity, want[i].Statements)
+		}
+	}
+
+	tests := []struct {
+		maxComplexity, maxStatements int
+		problems                     int
+	}{
+		{0, 0, 0},
+		{7, 7, 0},
+		{6, 0, 1},
+		{0, 6, 1},
+		{6, 6, 2},
+	}
+	for _, test := range tests {
+		l := &Linter{MaxComplexity: test.maxComplexity, MaxStatements: test.maxStatements}
+		ps, err := l.Lint("foo.go", []byte(src))
+		if err != nil {
+			t.Fatalf("Linting: %v", err)
+		}
+		n := 0
+		for _, p := range ps {
+			if p.Category == "complexity" {
+				n++
+			}
+		}
+		if n != test.problems {
+			t.Errorf("MaxComplexity %d, MaxStatements %d: got %d complexity problems, want %d", test.maxComplexity, test.maxStatements, n, test.problems)
+		}
+	}
+}
+
+func TestExampleNames(t *testing.T) {
+	files := map[string][]byte{
+		"foo.go": []byte(`package foo
+
+type T struct{}
+
+func (T) M() {}
+
+func F() {}
+`),
+		"foo_test.go": []byte(`package foo
+
+func Example() {}
+
+func Example_suffix() {}
+
+func ExampleF() {}
+
+func ExampleF_suffix() {}
+
+func ExampleT() {}
+
+func ExampleT_M() {}
+
+func ExampleT_M_suffix() {}
+
+func ExampleG() {}
+
+func ExampleT_N() {}
+
+func ExampleF_M() {}
+`),
+	}
+	ps, err := new(Linter).LintFiles(files)
+	if err != nil {
+		t.Fatalf("Linting: %v", err)
+	}
+	var got []string
+	for _, p := range ps {
+		if strings.Contains(p.Text, "unknown identifier") {
+			got = append(got, fmt.Sprintf("%s:%d: %s", p.Position.Filename, p.Position.Line, p.Text))
+		}
+	}
+	want := []string{
+		"foo_test.go:17: ExampleG refers to unknown identifier G",
+		"foo_test.go:19: ExampleT_N refers to unknown identifier T.N",
+		"foo_test.go:21: ExampleF_M refers to unknown identifier F.M",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Got example problems %q, want %q", got, want)
+	}
+
+	// Without the package's other files, its identifiers are unknown.
+	delete(files, "foo.go")
+	if ps, err = new(Linter).LintFiles(files); err != nil {
+		t.Fatalf("Linting: %v", err)
+	}
+	for _, p := range ps {
+		if strings.Contains(p.Text, "unknown identifier") {
+			t.Errorf("Unexpected problem at %s:%d: %v", p.Position.Filename, p.Position.Line, p.Text)
+		}
+	}
+}
+
+func TestDeadCodeAcrossFiles(t *testing.T) {
+	files := map[string][]byte{
+		"foo.go": []byte(`package foo
+
+func usedInTest() int { return 1 }
+
+func unused() int { return 2 }
+`),
+		"foo_test.go": []byte(`package foo
+
+import "testing"
+
+func TestFoo(t *testing.T) {
+	if usedInTest() != 1 {
+		t.Error("usedInTest() != 1")
+	}
+}
+`),
+	}
+	ps, err := new(Linter).LintFiles(files)
+	if err != nil {
+		t.Fatalf("Linting: %v", err)
+	}
+	var got []string
+	for _, p := range ps {
+		if p.Category == "dead-code" {
+			got = append(got, fmt.Sprintf("%s:%d: %s", p.Position.Filename, p.Position.Line, p.Text))
+		}
+	}
+	want := []string{"foo.go:5: func unused is unused"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Got dead code problems %q, want %q", got, want)
+	}
+}
+
 type instruction struct {
 	Line        int            // the line number this applies to
 	Match       *regexp.Regexp // what pattern to match
@@ -108,14 +439,16 @@ type instruction struct {
 }
 
 // parseInstructions parses instructions from the comments in a Go source file.
-// It returns nil if none were parsed.
-func parseInstructions(t *testing.T, filename string, src []byte) []instruction {
+// It returns nil if none were parsed, along with the set of problem categories
+// named by IGNORE instructions, which the file does not check.
+func parseInstructions(t *testing.T, filename string, src []byte) ([]instruction, map[string]bool) {
 	fset := token.NewFileSet()
 	f, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
 	if err != nil {
 		t.Fatalf("Test file %v does not parse: %v", filename, err)
 	}
 	var ins []instruction
+	ignore := make(map[string]bool)
 	for _, cg := range f.Comments {
 		ln := fset.Position(cg.Pos()).Line
 		raw := cg.Text()
@@ -128,6 +461,11 @@ func parseInstructions(t *testing.T, filename string, src []byte) []instruction
 				ins = make([]instruction, 0)
 				continue
 			}
+			if strings.HasPrefix(line, "IGNORE ") {
+				// e.g. "IGNORE dead-code" for files that declare things they don't use
+				ignore[strings.TrimPrefix(line, "IGNORE ")] = true
+				continue
+			}
 			if strings.Contains(line, "MATCH") {
 				rx, err := extractPattern(line)
 				if err != nil {
@@ -155,7 +493,7 @@ func parseInstructions(t *testing.T, filename string, src []byte) []instruction
 			}
 		}
 	}
-	return ins
+	return ins, ignore
 }
 
 func extractPattern(line string) (*regexp.Regexp, error) {
@@ -288,3 +626,444 @@ func TestExportedType(t *testing.T) {
 		}
 	}
 }
+
+// applyEdits applies the edits of a problem to src.
+func applyEdits(src []byte, edits []Edit) string {
+	var buf bytes.Buffer
+	last := 0
+	for _, e := range edits {
+		buf.Write(src[last:e.Pos.Offset])
+		buf.WriteString(e.New)
+		last = e.End.Offset
+	}
+	buf.Write(src[last:])
+	return buf.String()
+}
+
+func hasFix(p *Problem) bool {
+	return p.Edits != nil || p.ReplacementLine != ""
+}
+
+func TestEdits(t *testing.T) {
+	tests := []struct {
+		category  string
+		linter    Linter
+		src, want string
+	}{
+		{
+			category: "arg-order",
+			src: `func f() (error, int) {
+	if true {
+		return nil, 1
+	}
+	g := func() (int, error) { return 0, nil }
+	_ = g
+	return nil, 0
+}`,
+			want: `func f() (int, error) {
+	if true {
+		return 1, nil
+	}
+	g := func() (int, error) { return 0, nil }
+	_ = g
+	return 0, nil
+}`,
+		},
+		{
+			category: "arg-order",
+			src: `func f() (a, b error, c int) {
+	if c > 0 {
+		return
+	}
+	return nil, nil, 0
+}`,
+			want: `func f() (c int, a, b error) {
+	if c > 0 {
+		return
+	}
+	return 0, nil, nil
+}`,
+		},
+		{
+			category: "arg-order",
+			src:      `func f() (error, string, int) { return nil, "x", 2 }`,
+			want:     `func f() (string, int, error) { return "x", 2, nil }`,
+		},
+		{
+			// A return statement that can't be rewritten means no edits.
+			category: "arg-order",
+			src: `func f() (error, int) { return g() }
+func g() (error, int) { return nil, 0 }`,
+		},
+		{
+			category: "simplify",
+			src: `func f(a, b int) bool {
+	if a < b {
+		return false
+	} else {
+		return true
+	}
+}`,
+			want: `func f(a, b int) bool {
+	return !(a < b)
+}`,
+		},
+		{
+			category: "type-inference",
+			src: `type T struct{ A int }
+
+var ps = []*T{&T{1}, &T{
+	A: 2,
+}}`,
+			want: `type T struct{ A int }
+
+var ps = []*T{{1}, {
+	A: 2,
+}}`,
+		},
+		{
+			category: "imports",
+			src: `import (
+	"github.com/golang/example/stringutil"
+	fmt "fmt"
+
+	// os is for Exit.
+	"os" // not osext
+)`,
+			want: `import (
+	"fmt"
+	// os is for Exit.
+	"os" // not osext
+
+	"github.com/golang/example/stringutil"
+)`,
+		},
+		{
+			// The comment between the groups would be lost.
+			category: "imports",
+			src: `import (
+	"github.com/golang/example/stringutil"
+
+	// Keep these last; see issue 12.
+
+	"fmt"
+)`,
+		},
+		{
+			category: "errors",
+			src: `import (
+	"errors"
+	"fmt"
+)
+
+func f(x int) error {
+	return errors.New(fmt.Sprintf(
+		"x is %d",
+		x,
+	))
+}`,
+			want: `import (
+	"errors"
+	"fmt"
+)
+
+func f(x int) error {
+	return fmt.Errorf(
+		"x is %d",
+		x,
+	)
+}`,
+		},
+		{
+			category: "imports",
+			linter:   Linter{LocalPrefix: "example.com/local,example.org/"},
+			src: `import (
+	"example.org/y"
+	"fmt"
+
+	"example.com/local/x"
+	"github.com/golang/example/stringutil"
+)`,
+			want: `import (
+	"fmt"
+
+	"github.com/golang/example/stringutil"
+
+	"example.com/local/x"
+	"example.org/y"
+)`,
+		},
+		{
+			category: "slice",
+			src: `func f() []int {
+	x := make([]int, 0)
+	return x
+}`,
+			want: `func f() []int {
+	var x []int
+	return x
+}`,
+		},
+		{
+			category: "slice",
+			src:      `func f() []int { return make([]int, 0, 0) }`,
+			want:     `func f() []int { return make([]int, 0) }`,
+		},
+		{
+			category: "slice",
+			src: `func f(s []string) []int {
+	x := make([]int, 0)
+	for _, v := range s {
+		x = append(x, len(v))
+	}
+	return x
+}`,
+			want: `func f(s []string) []int {
+	x := make([]int, 0, len(s))
+	for _, v := range s {
+		x = append(x, len(v))
+	}
+	return x
+}`,
+		},
+		{
+			category: "slice",
+			src: `func f() []int {
+	x := make([]int, 0, 0)
+	return x
+}`,
+			want: `func f() []int {
+	var x []int
+	return x
+}`,
+		},
+		{
+			category: "indent",
+			src: `func f(xs []int) {
+	for _, x := range xs {
+		if x < 0 {
+			continue
+		} else {
+			y := x * 2
+
+			println(y)
+		}
+	}
+}`,
+			want: `func f(xs []int) {
+	for _, x := range xs {
+		if x < 0 {
+			continue
+		}
+		y := x * 2
+
+		println(y)
+	}
+}`,
+		},
+		{
+			// y would be redeclared.
+			category: "indent",
+			src: `func f(x int) {
+	if x < 0 {
+		return
+	} else {
+		y := x * 2
+		println(y)
+	}
+	y := 1
+	println(y)
+}`,
+		},
+		{
+			// The short variable declaration would go out of scope.
+			category: "indent",
+			src: `func f(g func() int) {
+	if x := g(); x < 0 {
+		return
+	} else {
+		println(x)
+	}
+}`,
+		},
+		{
+			category: "unused",
+			src: `func f(a, b int) int {
+	return a
+}`,
+			want: `func f(a, _ int) int {
+	return a
+}`,
+		},
+		{
+			category: "unused",
+			src: `type T int
+
+func (t *T) f(a int) int {
+	return a
+}`,
+			want: `type T int
+
+func (*T) f(a int) int {
+	return a
+}`,
+		},
+		{
+			category: "unary-op",
+			src: `const one = 1
+
+func f(n int) {
+	for i := 0; i < n; i += one {
+	}
+}`,
+			want: `const one = 1
+
+func f(n int) {
+	for i := 0; i < n; i++ {
+	}
+}`,
+		},
+		{
+			category: "range-loop",
+			src: `func f(s []string) {
+	for i := 0; i < len(s); i++ {
+		println(s[i], len(s[i]))
+	}
+}`,
+			want: `func f(s []string) {
+	for _, v := range s {
+		println(v, len(v))
+	}
+}`,
+		},
+		{
+			category: "range-loop",
+			src: `func f(m map[string]int) {
+	for k := range m {
+		println(k, m[k])
+	}
+	for _ = range m {
+	}
+}`,
+			want: `func f(m map[string]int) {
+	for k, v := range m {
+		println(k, v)
+	}
+	for _ = range m {
+	}
+}`,
+		},
+		{
+			// push may append to q.items, which a range would not see.
+			category: "range-loop",
+			src: `type queue struct{ items []int }
+
+func (q *queue) push(n int) { q.items = append(q.items, n) }
+
+func f(q *queue) {
+	for i := 0; i < len(q.items); i++ {
+		q.push(q.items[i])
+	}
+}`,
+		},
+		{
+			// The header spans more than one line.
+			category: "range-loop",
+			src: `func f(s []string) {
+	for i := 0; i <
+		len(s); i++ {
+		println(s[i])
+	}
+}`,
+		},
+		{
+			category: "time",
+			src: `import "time"
+
+type config struct {
+	delayMs time.Duration
+}
+
+func f(timeoutSecs time.Duration) config {
+	return config{delayMs: timeoutSecs * 2}
+}`,
+			want: `import "time"
+
+type config struct {
+	delay time.Duration
+}
+
+func f(timeoutSecs time.Duration) config {
+	return config{delay: timeoutSecs * 2}
+}`,
+		},
+		{
+			category: "time",
+			linter:   Linter{TimeSuffixes: []string{"Jiffies"}},
+			src: `import "time"
+
+func f(timeoutJiffies time.Duration) time.Duration {
+	return timeoutJiffies
+}`,
+			want: `import "time"
+
+func f(timeout time.Duration) time.Duration {
+	return timeout
+}`,
+		},
+		{
+			// The new name is already in use.
+			category: "time",
+			src: `import "time"
+
+func f(timeoutSecs time.Duration) {
+	timeout := 1
+	_, _ = timeout, timeoutSecs
+}`,
+		},
+	}
+	for _, test := range tests {
+		src := []byte("package foo\n\n" + test.src + "\n")
+		ps, err := test.linter.Lint("foo.go", src)
+		if err != nil {
+			t.Fatalf("Linting %q: %v", test.src, err)
+		}
+		// Use the first problem of the category,
+		// or the first with a fix if one is wanted.
+		var p *Problem
+		for i := range ps {
+			if ps[i].Category != test.category {
+				continue
+			}
+			if p == nil || (!hasFix(p) && test.want != "") {
+				p = &ps[i]
+			}
+		}
+		if p == nil {
+			t.Errorf("Linting %q: no %s problem", test.src, test.category)
+			continue
+		}
+		if p.Edits != nil && p.ReplacementLine != "" {
+			t.Errorf("Linting %q: got both edits %v and replacement line %q", test.src, p.Edits, p.ReplacementLine)
+		}
+		if test.want == "" {
+			if hasFix(p) {
+				t.Errorf("Linting %q: got edits %v and replacement line %q, want no fix", test.src, p.Edits, p.ReplacementLine)
+			}
+			continue
+		}
+		edits := p.Edits
+		if p.ReplacementLine != "" {
+			// Replace the whole line of the problem, without its newline.
+			line := p.Position
+			line.Offset -= line.Column - 1
+			end := line
+			end.Offset += bytes.IndexByte(src[line.Offset:], '\n')
+			edits = []Edit{{Pos: line, End: end, New: p.ReplacementLine}}
+		}
+		got := applyEdits(src, edits)
+		if want := "package foo\n\n" + test.want + "\n"; got != want {
+			t.Errorf("Applying edits to %q:\ngot  %q\nwant %q", test.src, got, want)
+		}
+	}
+}
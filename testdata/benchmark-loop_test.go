@@ -0,0 +1,19 @@
+// Test that benchmarks written with b.Loop use the iteration count.
+// IGNORE dead-code
+
+// Package foo ...
+package foo
+
+import "testing"
+
+func f() {}
+
+func BenchmarkLoop(b *testing.B) { // ok
+	for b.Loop() {
+		f()
+	}
+}
+
+func BenchmarkNoLoop(b *testing.B) { // MATCH /benchmark BenchmarkNoLoop never uses b.N/
+	f()
+}
@@ -0,0 +1,51 @@
+// Test for unused unexported declarations.
+
+// Package foo ...
+package foo
+
+import (
+	_ "unsafe" // for go:linkname
+)
+
+type used int
+
+type unusedType int // MATCH /type unusedType is unused/
+
+const (
+	first = iota // MATCH /const first is unused/
+	second
+)
+
+var counter, total int // MATCH /var total is unused/
+
+var _ = second
+
+// Exported is exported.
+func Exported() int {
+	counter++
+	var u used
+	return int(u) + helper()
+}
+
+func helper() int {
+	return 1
+}
+
+func recursive(n int) int { // MATCH /func recursive is unused/
+	if n == 0 {
+		return 0
+	}
+	return recursive(n - 1)
+}
+
+func (used) method() {} // ok, methods are not top-level identifiers
+
+func init() {}
+
+func main() {}
+
+//go:linkname nanotime runtime.nanotime
+func nanotime() int64
+
+//export callback
+func callback() {}
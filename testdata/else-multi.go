@@ -1,4 +1,5 @@
 // Test of return+else warning; should not trigger on multi-branch if/else.
+// IGNORE dead-code
 // OK
 
 // Package pkg ...
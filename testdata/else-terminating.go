@@ -0,0 +1,72 @@
+// Test of else warning after terminating statements.
+// IGNORE dead-code
+
+// Package pkg ...
+package pkg
+
+import (
+	"log"
+	"os"
+	"testing"
+)
+
+func f(xs []int, t *testing.T, l *log.Logger) {
+	for _, x := range xs {
+		if x == 0 {
+			continue
+		} else { // MATCH /if block ends with a continue statement, so drop this else/
+			log.Print(x)
+		}
+		if x < 0 {
+			break
+		} else { // MATCH /if block ends with a break statement/
+			log.Print(x)
+		}
+	}
+	if len(xs) == 0 {
+		goto done
+	} else { // MATCH /if block ends with a goto statement/
+		log.Print(xs)
+	}
+	if xs == nil {
+		panic("nil")
+	} else { // MATCH /if block ends with a call to panic/
+		log.Print(xs)
+	}
+	if len(xs) > 10 {
+		os.Exit(1)
+	} else { // MATCH /if block ends with a call to os.Exit/
+		log.Print(xs)
+	}
+	if len(xs) > 9 {
+		log.Fatalf("too many: %d", len(xs))
+	} else { // MATCH /if block ends with a call to log.Fatalf/
+		log.Print(xs)
+	}
+	if len(xs) > 8 {
+		l.Panic("too many")
+	} else { // MATCH /if block ends with a call to l.Panic/
+		log.Print(xs)
+	}
+	if len(xs) > 7 {
+		t.Fatal("too many")
+	} else { // MATCH /if block ends with a call to t.Fatal/
+		log.Print(xs)
+	}
+	if n := len(xs); n > 6 {
+		t.Skip("too many")
+	} else { // MATCH /if block ends with a call to t.Skip, so drop this else and outdent its block \(move short variable declaration/
+		log.Print(n)
+	}
+	if len(xs) > 5 {
+		t.Log("many")
+	} else { // ok
+		log.Print(xs)
+	}
+	if len(xs) > 4 {
+		l.Print("many")
+	} else { // ok
+		log.Print(xs)
+	}
+done:
+}
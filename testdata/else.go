@@ -1,4 +1,5 @@
 // Test of return+else warning.
+// IGNORE dead-code
 
 // Package pkg ...
 package pkg
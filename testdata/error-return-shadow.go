@@ -0,0 +1,12 @@
+// Test for returning a local type that shadows error.
+// IGNORE dead-code
+
+// Package foo ...
+package foo
+
+type error struct{} // MATCH /type error shadows the builtin error/
+
+// Check for a local type called error in the first location.
+func f() (error, int) { // ok
+	return error{}, 0
+}
@@ -1,4 +1,5 @@
 // Test for returning errors.
+// IGNORE dead-code
 
 // Package foo ...
 package foo
@@ -41,3 +42,32 @@ func l() (int, error, int) { // MATCH /error should be the last type/
 func m() (x int, err error, y int) { // MATCH /error should be the last type/
 	return 0, nil, 0
 }
+
+// Check for error in the wrong location with grouped named variables.
+func n() (a, b error, c int) { // MATCH /error should be the last type/
+	return nil, nil, 0
+}
+
+// Check for multiple errors at the end.
+func o() (int, error, error) { // ok
+	return 0, nil, nil
+}
+
+type myError struct{}
+
+func (*myError) Error() string { return "oops" }
+
+// Check for a named type that implements error in the wrong location.
+func p() (*myError, int) { // MATCH /error should be the last type/
+	return nil, 0
+}
+
+// Check for a named type that implements error at the end.
+func q() (int, *myError) { // ok
+	return 0, nil
+}
+
+// Check for a type that does not implement error.
+func r() (myError, int) { // ok
+	return myError{}, 0
+}
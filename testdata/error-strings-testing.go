@@ -0,0 +1,13 @@
+// Test that test failure messages are not checked as error strings.
+// OK
+
+// Package foo ...
+package foo
+
+import "testing"
+
+// Check reports test failures.
+func Check(t *testing.T, tb testing.TB, got, want int) {
+	t.Errorf("Get(%q) = %d, want %d", "key", got, want)
+	tb.Errorf("Something failed.")
+}
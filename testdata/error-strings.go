@@ -0,0 +1,61 @@
+// Test for error strings of wrapped and custom errors.
+// IGNORE dead-code
+
+// Package foo ...
+package foo
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	msg     = "Something went wrong."
+	okMsg   = "something went wrong"
+	prefix  = "bad input"
+	badPart = ":"
+)
+
+type logger struct{}
+
+func (logger) Errorf(format string, args ...interface{}) error { return nil }
+func (logger) Infof(format string, args ...interface{})        {}
+
+func f(x int, err error, l logger) error {
+	switch x {
+	case 0:
+		return errors.New("Something failed") // MATCH /error strings should not be capitalized/
+	case 1:
+		return errors.Wrap(err, "reading config.") // MATCH /error strings should not end with punctuation/
+	case 2:
+		return errors.Wrapf(err, "reading %d", x) // ok
+	case 3:
+		return errors.Errorf("Read %d failed!", x) // MATCH /error strings should not be capitalized and should not end with punctuation/
+	case 4:
+		return fmt.Errorf(msg) // MATCH /error strings should not be capitalized and should not end with punctuation/
+	case 5:
+		return fmt.Errorf(okMsg) // ok
+	case 6:
+		return fmt.Errorf(prefix + badPart) // MATCH /error strings should not end with punctuation/
+	case 7:
+		l.Infof("Done.")           // ok
+		return l.Errorf("Failed.") // MATCH /error strings should not be capitalized and should not end with punctuation/
+	}
+	return nil
+}
+
+// Config is a configuration.
+type Config struct{}
+
+func g(x int) error {
+	switch x {
+	case 0:
+		return fmt.Errorf("Config %d is invalid", x) // ok
+	case 1:
+		return fmt.Errorf("Errorf failed for %d", x) // ok
+	case 2:
+		return fmt.Errorf("Config.") // MATCH /error strings should not end with punctuation/
+	}
+	return fmt.Errorf("Invalid config %d", x) // MATCH /error strings should not be capitalized/
+}
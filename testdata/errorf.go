@@ -1,4 +1,5 @@
 // Test for not using fmt.Errorf or testing.Errorf.
+// IGNORE dead-code
 
 // Package foo ...
 package foo
@@ -6,6 +7,7 @@ package foo
 import (
 	"errors"
 	"fmt"
+	"log"
 	"testing"
 )
 
@@ -38,3 +40,40 @@ func TestF(t *testing.T) error {
 }
 
 func g(s string) string { return "prefix: " + s }
+
+// BenchmarkF is a dummy benchmark
+func BenchmarkF(b *testing.B) {
+	x := 1
+	b.Fatal(fmt.Sprintf("something %d", x)) // MATCH /should replace b\.Fatal\(fmt\.Sprintf\(\.\.\.\)\) with b\.Fatalf\(\.\.\.\)/ -> `	b.Fatalf("something %d", x)`
+
+	b.Error(fmt.Sprintf("something %d", x)) // MATCH /should replace b\.Error\(fmt\.Sprintf\(\.\.\.\)\) with b\.Errorf\(\.\.\.\)/ -> `	b.Errorf("something %d", x)`
+
+	b.Log(fmt.Sprint(x)) // ok
+}
+
+type logger struct{}
+
+func (logger) Warn(v ...interface{})                 {}
+func (logger) Warnf(format string, v ...interface{}) {}
+func (logger) Info(v ...interface{})                 {}
+func (logger) Infof(v ...interface{})                {}
+
+func h(x int, l logger) {
+	log.Print(fmt.Sprintf("something %d", x)) // MATCH /should replace log\.Print\(fmt\.Sprintf\(\.\.\.\)\) with log\.Printf\(\.\.\.\)/ -> `	log.Printf("something %d", x)`
+
+	fmt.Print(fmt.Sprintf("something %d", x)) // MATCH /should replace fmt\.Print\(fmt\.Sprintf\(\.\.\.\)\) with fmt\.Printf\(\.\.\.\)/
+	l.Warn(fmt.Sprintf("something %d", x))    // MATCH /should replace l\.Warn\(fmt\.Sprintf\(\.\.\.\)\) with l\.Warnf\(\.\.\.\)/
+	l.Info(fmt.Sprintf("something %d", x))    // ok
+	warn(fmt.Sprintf("something %d", x))      // MATCH /should replace warn\(fmt\.Sprintf\(\.\.\.\)\) with warnf\(\.\.\.\)/
+	fmt.Println(fmt.Sprintf("x"), x)          // ok
+	if x > 10 {
+		panic(fmt.Sprintf("something %d", x)) // MATCH /should replace panic\(fmt\.Sprintf\(\.\.\.\)\) with panic\(fmt\.Errorf\(\.\.\.\)\)/
+	}
+	log.Fatal(fmt.Sprintf( // MATCH /should replace log\.Fatal\(fmt\.Sprintf\(\.\.\.\)\) with log\.Fatalf\(\.\.\.\)/
+		"something %d",
+		x,
+	))
+}
+
+func warn(s string)                            {}
+func warnf(format string, args ...interface{}) {}
@@ -1,4 +1,5 @@
 // Test for naming errors.
+// IGNORE dead-code
 
 // Package foo ...
 package foo
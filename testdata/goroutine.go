@@ -0,0 +1,68 @@
+// Test for goroutines started without a clear lifetime.
+// IGNORE dead-code
+
+// Package foo ...
+package foo
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Server serves.
+type Server struct {
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// Start starts a goroutine that runs forever.
+func Start() {
+	go func() { // MATCH /goroutine started by exported func Start has no clear lifetime/
+		for {
+			time.Sleep(time.Second)
+		}
+	}()
+}
+
+// StartContext starts a goroutine that stops with ctx.
+func StartContext(ctx context.Context) {
+	go func() { // ok
+		<-ctx.Done()
+	}()
+}
+
+// StartArg starts a goroutine that is passed its context.
+func StartArg(ctx context.Context) {
+	go func(ctx context.Context) { // ok
+		<-ctx.Done()
+	}(ctx)
+}
+
+// Serve starts goroutines tracked by s.
+func (s *Server) Serve() {
+	go func() { // ok
+		<-s.done
+	}()
+	s.wg.Add(1)
+	go func() { // ok
+		defer s.wg.Done()
+	}()
+	go func() { // MATCH /goroutine started by exported method Serve has no clear lifetime/
+		done := make(chan bool)
+		close(done)
+	}()
+}
+
+// StartUndefined starts a goroutine with an argument that does not type check.
+func StartUndefined() {
+	go func(x int) { // MATCH /goroutine started by exported func StartUndefined has no clear lifetime/
+		println(x)
+	}(undefinedVar)
+}
+
+func start() {
+	go func() { // ok
+		select {}
+	}()
+}
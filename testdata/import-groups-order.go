@@ -0,0 +1,19 @@
+// Test of import group ordering.
+
+// Package foo ...
+package foo
+
+import (
+	"github.com/golang/example/stringutil"
+
+	"os" // MATCH /standard library imports should come before third-party imports/
+	"strings"
+)
+
+import (
+	"fmt"
+
+	"golang.org/x/net/context"
+) // ok
+
+var _, _, _, _, _ = stringutil.Reverse, os.Exit, strings.Split, fmt.Println, context.Background
@@ -0,0 +1,13 @@
+// Test of import grouping.
+
+// Package foo ...
+package foo
+
+import (
+	"fmt"
+	"github.com/golang/example/stringutil" // MATCH /third-party imports should be in a separate group from standard library imports/
+
+	"os"
+)
+
+var _, _, _ = fmt.Println, stringutil.Reverse, os.Exit
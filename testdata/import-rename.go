@@ -0,0 +1,16 @@
+// Test of import renames.
+
+// Package foo ...
+package foo
+
+import (
+	fmt "fmt"                 // MATCH /import of "fmt" should not be renamed to fmt, which is already its package name/
+	myOS "os"                 // MATCH /don't use MixedCaps in import name myOS; rename it to myos/
+	tmpl "text/template"      // ok
+	html_tmpl "html/template" // MATCH /don't use an underscore in import name html_tmpl; rename it to htmltmpl/
+	STRINGS "strings"         // MATCH /don't use ALL_CAPS in import name STRINGS; drop the rename/
+	stringutil "strings"      // ok
+	URL_PKG "net/url"         // MATCH /don't use ALL_CAPS in import name URL_PKG; rename it to urlpkg/
+)
+
+var _, _, _, _, _, _, _ = fmt.Println, myOS.Exit, tmpl.New, html_tmpl.New, STRINGS.Split, stringutil.Split, URL_PKG.Parse
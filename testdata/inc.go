@@ -1,14 +1,36 @@
 // Test for use of x++ and x--.
+// IGNORE dead-code
 
 // Package pkg ...
 package pkg
 
+const one = 1
+
+type step int
+
+const unit step = 1
+
 func addOne(x int) int {
-	x += 1 // MATCH /x\+\+/
+	x += 1 // MATCH /x\+\+/ -> `	x++`
 	return x
 }
 
 func subOneInLoop(y int) {
-	for ; y > 0; y -= 1 { // MATCH /y--/
+	for ; y > 0; y -= 1 { // MATCH /y--/ -> `	for ; y > 0; y-- {`
 	}
 }
+
+func addConst(x int, s step, a []int) {
+	x += one // MATCH /should replace x \+= one with x\+\+/ -> `	x++`
+
+	s -= unit      // MATCH /s--/
+	a[0] += 2 - 1  // MATCH /a\[0\]\+\+/
+	x += 2         // ok
+	x += 1.0       // MATCH /x\+\+/
+	x -= one * one // MATCH /x--/
+}
+
+func addFloat(f float64, c complex128) {
+	f += 1 // ok
+	c -= 1 // ok
+}
@@ -1,4 +1,5 @@
 // Test for pointless make() calls.
+// IGNORE dead-code
 
 // Package pkg ...
 package pkg
@@ -10,10 +11,63 @@ type T int
 
 var z []T
 
+var w = make([]T, 0) // ok, because w may be observed where nil and empty differ
+
 func f() {
 	x := make([]T, 0)            // MATCH /var x \[\]T/
 	y := make([]http.Request, 0) // MATCH /var y \[\]http\.Request/
 	z = make([]T, 0)             // ok, because we don't know where z is declared
 
-	_, _ = x, y
+	var v = make([]T, 0) // MATCH /can probably use "var v \[\]T" instead/
+
+	var s interface{} = make([]T, 0) // ok
+
+	x = make([]T, 0) // ok, because x may be observed where nil and empty differ
+
+	c := make([]T, 5, 0) // ok; this is a different problem
+
+	a := make([]T, 0, 0) // MATCH /can probably use "var a \[\]T" instead/
+
+	_, _, _, _, _, _ = x, y, v, s, c, a
+}
+
+func initStmt(c bool) {
+	if x := make([]T, 0); c { // ok, cannot be a var declaration
+		_ = x
+	}
+	switch y := make([]T, 0); { // ok
+	default:
+		_ = y
+	}
+}
+
+func g() []T {
+	return make([]T, 0, 0) // MATCH /should omit zero capacity argument to make/
+}
+
+func h(ts []T, m map[string]T, ch chan T) {
+	var xs []T
+	xs = make([]T, 0) // MATCH /should preallocate xs with make\(\[\]T, 0, len\(ts\)\)/
+	for _, t := range ts {
+		xs = append(xs, t)
+	}
+
+	ys := make([]T, 0, 0) // MATCH /should preallocate ys with make\(\[\]T, 0, len\(m\)\)/
+	for _, t := range m {
+		ys = append(ys, t)
+	}
+
+	zs := make([]T, 0) // MATCH /var zs \[\]T/
+	for t := range ch {
+		zs = append(zs, t)
+	}
+
+	as := make([]T, 0) // MATCH /var as \[\]T/
+	for _, t := range ts {
+		if t > 0 {
+			as = append(as, t)
+		}
+	}
+
+	_, _, _, _ = xs, ys, zs, as
 }
@@ -0,0 +1,57 @@
+// Test for embedded mutexes and copied locks.
+// IGNORE dead-code
+
+// Package foo ...
+package foo
+
+import "sync"
+
+// Cache is a cache.
+type Cache struct {
+	sync.Mutex // MATCH /exported struct Cache embeds sync.Mutex/
+	m          map[string]string
+}
+
+// Store is a store.
+type Store struct {
+	*sync.RWMutex // MATCH /exported struct Store embeds sync.RWMutex/
+}
+
+type cache struct {
+	sync.Mutex // ok
+	inner      inner
+}
+
+// Guarded is a guarded value.
+type Guarded struct {
+	mu sync.Mutex // ok
+	n  int
+}
+
+type inner struct {
+	locks [2]sync.RWMutex
+}
+
+// Len returns the length of the cache.
+func (c Cache) Len() int { // MATCH /method Cache.Len has a value receiver, but Cache contains a sync.Mutex/
+	return len(c.m)
+}
+
+// N returns the value.
+func (g Guarded) N() int { // MATCH /method Guarded.N has a value receiver, but Guarded contains a sync.Mutex/
+	return g.n
+}
+
+func (i inner) lock() { // MATCH /method inner.lock has a value receiver, but inner contains a sync.RWMutex/
+}
+
+// Get returns the value.
+func (g *Guarded) Get() int { // ok
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.n
+}
+
+// Lock locks the store.
+func (s Store) Lock() { // ok
+}
@@ -1,4 +1,5 @@
 // Test for name linting.
+// IGNORE dead-code
 
 // Package pkg_with_underscores ...
 package pkg_with_underscores // MATCH /underscore.*package name/
@@ -6,7 +7,7 @@ package pkg_with_underscores // MATCH /underscore.*package name/
 import (
 	"io"
 	"net"
-	net_http "net/http" // renamed deliberately
+	net_http "net/http" // MATCH /don't use an underscore in import name net_http; rename it to nethttp/
 	"net/url"
 )
 
@@ -79,7 +80,7 @@ type case2_1 struct {
 	case2_2 int
 }
 
-func case3_1(case3_2 int) (case3_3 string) {
+func case3_1(case3_2 int) (case3_3 string) { // MATCH /func case3_1 has unused parameter case3_2/
 	case3_4 := 4
 	_ = case3_4
 
@@ -0,0 +1,84 @@
+// Test for deeply nested code.
+// IGNORE dead-code
+
+// Package pkg ...
+package pkg
+
+import "log"
+
+func f(xs [][]int, m map[int]bool) {
+	for _, x := range xs {
+		for _, y := range x {
+			if y > 0 {
+				switch {
+				case m[y]:
+					if y > 10 {
+						for i := 0; i < y; i++ { // MATCH /block is nested 6 levels deep, more than 5/
+							if i > 5 {
+								log.Print(i)
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func g(x int) {
+	if x > 0 { // MATCH /invert its condition and return early \(if !\(x > 0\) { return }\)/
+		if x > 1 {
+			if x > 2 {
+				if x > 3 {
+					if x > 4 { // ok, else-if chains are nested equally deep
+						log.Print(x)
+					} else if x > 5 {
+						log.Print(x)
+					} else if x > 6 {
+						log.Print(x)
+					}
+					func() {
+						if x > 5 { // ok, function literals are examined on their own
+							log.Print(x)
+						}
+					}()
+				}
+			}
+		}
+	}
+}
+
+func h(x int, ok bool) {
+	log.Print(x)
+	if ok { // MATCH /if block ends the function, so invert its condition and return early \(if !ok { return }\) to outdent its 8 lines/
+		log.Print(x)
+		log.Print(x)
+		log.Print(x)
+		log.Print(x)
+		log.Print(x)
+		log.Print(x)
+		log.Print(x)
+		log.Print(x)
+	}
+}
+
+func i(x int) {
+	log.Print(x)
+	if x > 0 { // ok, small
+		log.Print(x)
+	}
+}
+
+func j(x int) int {
+	if x > 0 { // ok, has results
+		log.Print(x)
+		log.Print(x)
+		log.Print(x)
+		log.Print(x)
+		log.Print(x)
+		log.Print(x)
+		log.Print(x)
+		log.Print(x)
+	}
+	return x
+}
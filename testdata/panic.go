@@ -0,0 +1,80 @@
+// Test for panics in exported functions of library packages.
+// IGNORE dead-code
+
+// Package foo ...
+package foo
+
+import "errors"
+
+// Color is a color.
+type Color int
+
+// These are the colors.
+const (
+	Red Color = iota
+	Green
+	Blue
+)
+
+// F does not handle errors well.
+func F(x int) int {
+	if x < 0 {
+		panic("negative x") // MATCH /func F should not use panic for normal error handling/
+	}
+	return x
+}
+
+// MustF panics on error, as its name says.
+func MustF(x int) int {
+	if x < 0 {
+		panic(errors.New("negative x")) // MATCH /func MustF should not use panic/
+	}
+	return x
+}
+
+// Name returns the name of c.
+func (c Color) Name() string {
+	switch c {
+	case Red:
+		return "red"
+	case Green:
+		return "green"
+	case Blue:
+		return "blue"
+	default:
+		panic("unknown color") // MATCH /method Name should not use panic/
+	}
+}
+
+// Short returns the short name of c.
+func (c Color) Short() string {
+	switch c {
+	case Red:
+		return "r"
+	default:
+		panic("unknown color") // MATCH /method Short should not use panic/
+	}
+}
+
+func init() {
+	if Red != 0 {
+		panic("red is not zero") // MATCH /func init should not use panic/
+	}
+}
+
+func g() {
+	panic("unexported") // ok
+}
+
+type t int
+
+// F is a method of an unexported type.
+func (t) F() {
+	panic("unexported type") // ok
+}
+
+// G shadows panic.
+func G() {
+	panic := func(string) {} // MATCH /var panic shadows the builtin panic/
+	panic("not the builtin") // ok
+}
@@ -1,4 +1,5 @@
 // Test for range construction.
+// IGNORE dead-code
 
 // Package foo ...
 package foo
@@ -35,3 +36,97 @@ func f() {
 	for _, x = range m {
 	}
 }
+
+func g(s []string, m map[string]int, a *[4]int) {
+	for _ = range s { // MATCH /should omit values from range; this loop is equivalent to `for range ...`/ -> `	for range s {`
+	}
+	for _, _ = range m { // MATCH /should omit values from range/ -> `	for range m {`
+	}
+
+	for i := range s { // MATCH /should use the value form of range instead of indexing s\[i\]/
+		println(s[i])
+	}
+	for k := range m { // MATCH /should use the value form of range instead of indexing m\[k\]/
+		println(k, m[k])
+	}
+	for i := range a { // MATCH /indexing a\[i\]/
+		_ = a[i] + len(a)
+	}
+	for i := range s { // ok, modifies the elements
+		s[i] = s[i] + "x"
+	}
+	for i := range s { // ok, may modify the elements
+		h(s)
+		println(s[i])
+	}
+	for i := range s { // ok, does not read the elements
+		println(i)
+	}
+	for k := range m { // ok, modifies the map
+		delete(m, k)
+		println(m[k])
+	}
+}
+
+func h(s []string) {
+	for i := 0; i < len(s); i++ { // MATCH /should replace this loop with for _, v := range s/
+		println(s[i])
+	}
+	for i := 0; i < len(s); i++ { // MATCH /should replace this loop with for i := range s/ -> `	for i := range s {`
+		s[i] = "x"
+	}
+	for i := 0; i < len(s); i++ { // MATCH /should replace this loop with for range s/ -> `	for range s {`
+		println("x")
+	}
+	for i := 0; i < len(s); i++ { // MATCH /should replace this loop with for i, v := range s/
+		println(i, s[i])
+	}
+	for i := 0; i < len(s); i++ { // ok, modifies i
+		if s[i] == "" {
+			i++
+		}
+	}
+	for i := 0; i < len(s); i++ { // ok, modifies s
+		s = s[1:]
+	}
+	for i := 0; i < len(s)-1; i++ { // ok
+		println(s[i])
+	}
+	var v int
+	for i := 0; i < len(s); i++ { // MATCH /should replace this loop with for i := range s/
+		println(s[i], v)
+	}
+}
+
+type queue struct {
+	items []int
+}
+
+func (q *queue) push(n int) {
+	if n > 0 {
+		q.items = append(q.items, n-1)
+	}
+}
+
+var work []int
+
+func visit(n int) {
+	if n > 0 {
+		work = append(work, n-1)
+	}
+}
+
+func worklists(q *queue, r queue) {
+	for i := 0; i < len(q.items); i++ { // ok, no fix, since push may grow q.items: MATCH /should replace this loop with for _, v := range q.items if q.items does not grow during the loop/
+		q.push(q.items[i])
+	}
+	for i := 0; i < len(work); i++ { // ok, no fix, since visit may grow work: MATCH /if work does not grow during the loop/
+		visit(work[i])
+	}
+	for i := 0; i < len(r.items); i++ { // ok, no fix: MATCH /if r.items does not grow during the loop/
+		r.push(r.items[i])
+	}
+	for i := 0; i < len(r.items); i++ { // MATCH /should replace this loop with for _, v := range r.items$/
+		println(r.items[i])
+	}
+}
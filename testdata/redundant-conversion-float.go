@@ -0,0 +1,15 @@
+// Test that floating-point conversions that prevent fused multiply-add are kept.
+// OK
+
+// Package foo ...
+package foo
+
+// Sum returns p*q+r with p*q rounded before the addition.
+func Sum(p, q, r float64) float64 {
+	return float64(p*q) + r
+}
+
+// Scale returns x*y+z with x*y rounded before the addition.
+func Scale(x, y, z complex128) complex128 {
+	return complex128(x*y) + z
+}
@@ -0,0 +1,57 @@
+// Test for redundant conversions and composite literal types.
+// IGNORE dead-code
+
+// Package foo ...
+package foo
+
+// T is a pair.
+type T struct {
+	A, B int
+}
+
+// ID is an identifier.
+type ID int
+
+func f(x int, id ID, s string, bs []byte, y float64) {
+	a := int(x) // MATCH /should omit conversion of x to int/ -> `	a := x`
+
+	b := int(x+1) * 2 // MATCH /should omit conversion of x \+ 1 to int/ -> `	b := (x+1) * 2`
+
+	c := ID(id) // MATCH /should omit conversion of id to ID/ -> `	c := id`
+
+	d := int(id) // ok
+
+	e := float64(1) // ok
+
+	g := string(s) // MATCH /should omit conversion of s to string/ -> `	g := s`
+
+	h := []byte(bs) // MATCH /should omit conversion of bs to \[\]byte/ -> `	h := bs`
+
+	i := []byte(nil) // ok
+
+	j := ID(3) // ok
+
+	k := float64(y) // MATCH /should omit conversion of y to float64/ -> `	k := y`
+
+	_, _, _, _, _, _, _, _, _, _ = a, b, c, d, e, g, h, i, j, k
+}
+
+var ts = []T{
+	T{1, 2}, // MATCH /should omit type T from composite literal elements/
+	T{3, 4},
+	{5, 6},
+}
+
+var ps = []*T{&T{1, 2}, &T{A: 3}} // MATCH /should omit type T from composite literal elements/
+
+var m = map[T]T{
+	T{1, 2}: T{3, 4}, // MATCH /should omit type T from composite literal elements/
+}
+
+var nested = [][]int{
+	[]int{1}, // MATCH /should omit type \[\]int from composite literal elements/
+}
+
+var iface = []interface{}{T{1, 2}} // ok
+
+var mixed = []*T{{1, 2}, nil} // ok
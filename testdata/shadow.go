@@ -0,0 +1,37 @@
+// Test for declarations that shadow builtins and imported packages.
+// IGNORE dead-code
+
+// Package foo ...
+package foo
+
+import (
+	"net/url"
+	str "strings"
+)
+
+type string struct{} // MATCH /type string shadows the builtin string/
+
+func copy(dst, src []byte) {} // MATCH /func copy shadows the builtin copy/
+
+func f(u *url.URL) {
+	len := 3                         // MATCH /var len shadows the builtin len/
+	url := u.String()                // MATCH /var url shadows the import of package "net\/url"/
+	var str int                      // MATCH /var str shadows the import of package "strings"/
+	for new := 0; new < len; new++ { // MATCH /var new shadows the builtin new/
+	}
+	for _, cap := range url { // MATCH /var cap shadows the builtin cap/
+		_ = cap
+	}
+	_ = str
+	{
+		url := 1 // ok
+		_ = url
+	}
+}
+
+func g(s *url.URL) (url.Values, bool) { // ok
+	type x struct {
+		len int // ok
+	}
+	return s.Query(), true
+}
@@ -0,0 +1,63 @@
+// Test for simplifiable boolean and comparison expressions.
+// IGNORE dead-code
+
+// Package foo ...
+package foo
+
+func f(x bool, a, b int, s string) bool {
+	if x == true { // MATCH /should omit comparison to bool constant, can be simplified to x/ -> `	if x {`
+		return false
+	}
+	if false != x { // MATCH /should omit comparison to bool constant, can be simplified to x/ -> `	if x {`
+		return false
+	}
+	if g() == false { // MATCH /should omit comparison to bool constant, can be simplified to !g\(\)/ -> `	if !g() {`
+		return false
+	}
+	if (a < b) != true { // MATCH /simplified to !\(a < b\)/ -> `	if !(a < b) {`
+		return false
+	}
+	if !(a == b) { // MATCH /should replace !\(a == b\) with a != b/ -> `	if a != b {`
+		return false
+	}
+	if !(a < b) { // ok
+		return false
+	}
+	if len(s) == 0 { // MATCH /should replace len\(s\) == 0 with s == ""/ -> `	if s == "" {`
+		return false
+	}
+	if len(s) > 0 && a == 0 { // MATCH /should replace len\(s\) > 0 with s != ""/ -> `	if s != "" && a == 0 {`
+		return true
+	}
+	var bs []byte
+	if len(bs) == 0 { // ok
+		return true
+	}
+	if a == b { // MATCH /should replace this if statement with return a == b/
+		return true
+	} else { // ok, since the whole statement is replaced
+		return false
+	}
+}
+
+func g() bool {
+	true := false    // MATCH /var true shadows the builtin true/
+	if g() == true { // ok
+		return true
+	}
+	if x := g(); x {
+		return true
+	} else { // MATCH /if block ends with a return statement/
+		return false
+	}
+}
+
+func h(a, b int) bool {
+	if a < b { // ok
+		return false
+	} else if a == b {
+		return true
+	} else { // ok
+		return false
+	}
+}
@@ -0,0 +1,52 @@
+// Test for string concatenation in loops.
+// IGNORE dead-code
+
+// Package foo ...
+package foo
+
+type name string
+
+type row struct {
+	text string
+}
+
+func f(parts []string) string {
+	var s string
+	for _, p := range parts {
+		s += p // MATCH /string s is built with \+= in a loop/
+	}
+	n := 0
+	for i := 0; i < len(parts); i++ { // MATCH /should replace this loop with for _, v := range parts/
+		n += len(parts[i]) // ok
+		for range parts {
+			s += "," // MATCH /string s is built with \+= in a loop/
+		}
+	}
+	var nm name
+	var r row
+	for _, p := range parts {
+		nm += name(p) // MATCH /string nm is built with \+= in a loop/
+		r.text += p   // MATCH /string r.text is built with \+= in a loop/
+		t := ""
+		t += p // ok
+		_ = t
+		g := func() {
+			s += p // ok
+		}
+		g()
+	}
+	s += "done" // ok
+	return s + string(nm) + r.text
+}
+
+func lines(rows [][]string) []string {
+	var out []string
+	for _, r := range rows {
+		line := ""
+		for _, c := range r {
+			line += c // MATCH /string line is built with \+= in a loop/
+		}
+		out = append(out, line)
+	}
+	return out
+}
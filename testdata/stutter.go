@@ -1,4 +1,5 @@
 // Test of stuttery names.
+// IGNORE dead-code
 
 // Package donut ...
 package donut
@@ -0,0 +1,37 @@
+// Test of test failure message checks.
+
+// Package foo ...
+package foo
+
+import "testing"
+
+func f(x int) int { return x }
+
+func TestF(t *testing.T) {
+	if got, want := f(1), 1; got != want {
+		t.Errorf("f(1) = %v, want %v", got, want) // ok
+	}
+	if got := f(2); got != 2 {
+		t.Errorf("expected %v, got %v", 2, got) // MATCH /test failure message should report got and want rather than "expected"/
+	}
+	if got := f(3); got != 3 {
+		t.Fatalf("Actual: %v", got) // MATCH /rather than "Actual"/
+	}
+	if got := f(4); got != 4 {
+		t.Fatalf("want %v, got %v", 4, got) // MATCH /test failure message should report what it got before what it wants/
+	}
+	if _, err := g(); err != nil {
+		t.Fatalf("unexpected error: %v", err) // ok
+	}
+	t.Logf("want %v, got %v", 4, f(4)) // ok
+}
+
+func BenchmarkF(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if got := f(i); got != i {
+			b.Errorf("f(%d): expected %d", i, got) // MATCH /rather than "expected"/
+		}
+	}
+}
+
+func g() (int, error) { return 0, nil }
@@ -0,0 +1,67 @@
+// Test of test function checks.
+// IGNORE dead-code
+
+// Package foo ...
+package foo
+
+import "testing"
+
+type T struct{}
+
+func (T) M() {}
+
+func F() {}
+
+func TestF(t *testing.T) {}
+
+func Testfoo(t *testing.T) {} // MATCH /test Testfoo will not be run by go test, since Test is followed by a lower case letter; it should be TestFoo/
+
+func Test_foo_bar(t *testing.T) {} // MATCH /don't use underscores in test names; Test_foo_bar should be TestFooBar/
+
+func TestF_suffix(t *testing.T) {} // ok
+
+func Testdata() {} // ok
+
+func Benchmarkfoo(b *testing.B) { // MATCH /benchmark Benchmarkfoo will not be run by go test/
+	for i := 0; i < b.N; i++ {
+	}
+}
+
+func BenchmarkF(b *testing.B) { // MATCH /benchmark BenchmarkF never uses b.N/
+	F()
+}
+
+func BenchmarkSub(b *testing.B) { // ok
+	b.Run("f", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			F()
+		}
+	})
+}
+
+func BenchmarkHelper(b *testing.B) { // ok
+	benchmark(b)
+}
+
+func benchmark(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		F()
+	}
+}
+
+func check(t *testing.T, got, want int) { // MATCH /test helper check should call t.Helper\(\)/
+	if got != want {
+		t.Errorf("got %d, want %d", got, want)
+	}
+}
+
+func checkHelper(t testing.TB, got, want int) { // ok
+	t.Helper()
+	if got != want {
+		t.Fatalf("got %d, want %d", got, want)
+	}
+}
+
+func logIt(t *testing.T) { // ok
+	t.Log("no failures here")
+}
@@ -1,4 +1,5 @@
 // Test of time suffixes.
+// IGNORE dead-code
 
 // Package foo ...
 package foo
@@ -11,3 +12,26 @@ import (
 var rpcTimeoutMsec = flag.Duration("rpc_timeout", 100*time.Millisecond, "some flag") // MATCH /Msec.*\*time.Duration/
 
 var timeoutSecs = 5 * time.Second // MATCH /Secs.*time.Duration/
+
+type config struct {
+	retryDelayMs time.Duration // MATCH /struct field retryDelayMs is of type time.Duration; don't use unit-specific suffix "Ms"/
+	retries      int           // ok
+}
+
+func wait(timeoutSecs time.Duration) (elapsedMsec time.Duration) { // MATCH /func parameter timeoutSecs is of type time.Duration/
+	return timeoutSecs // MATCH:21 /func result elapsedMsec is of type time.Duration/
+}
+
+func sleep(delayMs int, count int) { // MATCH /func parameter delayMs has type int and unit-specific suffix "Ms"; consider using a time.Duration/
+	const n = 3
+	_ = count
+	timeout := time.Duration(delayMs) * time.Millisecond // ok
+	_ = time.Second * timeout                            // MATCH /should not multiply two time.Durations \(time.Second and timeout\)/
+	_ = 2 * time.Second                                  // ok
+	_ = n * timeout                                      // ok
+	_ = (timeout) * (timeout)                            // MATCH /should not multiply two time.Durations/
+}
+
+var pollIntervalMins = flag.Duration("poll_interval", time.Minute, "some flag") // MATCH /Mins.*\*time.Duration/
+
+var cacheTTLHours = 2 * time.Hour // MATCH /Hours.*time.Duration/
@@ -1,4 +1,5 @@
 // Test for unexported return types.
+// IGNORE dead-code
 
 // Package foo ...
 package foo
@@ -38,7 +39,7 @@ func unexp() hidden { // ok
 
 // This is slightly sneaky: we shadow the builtin "int" type.
 
-type int struct{}
+type int struct{} // MATCH /type int shadows the builtin int/
 
 // ExportedIntReturner returns an unexported type from this package.
 func ExportedIntReturner() int { // MATCH /ExportedIntReturner.*unexported.*int/
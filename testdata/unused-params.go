@@ -0,0 +1,84 @@
+// Test for unused function parameters and receivers.
+// IGNORE dead-code
+
+// Package foo ...
+package foo
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// T is a type.
+type T struct {
+	n int
+}
+
+func f(a, b int) int { // MATCH /func f has unused parameter b; rename it to _ or remove it/
+	return a * 2
+}
+
+// F is exported.
+func F(a, b int) int { // MATCH /exported func F has unused parameter b$/
+	return a * 2
+}
+
+func (t *T) g(x int) int { // MATCH /method g has unused parameter x; rename it to _ or remove it/
+	return t.n
+}
+
+func (t *T) h(x int) int { // MATCH /method h has unused receiver t; omit its name/
+	return x
+}
+
+// String has the signature of fmt.Stringer.String.
+func (t *T) String() string {
+	return fmt.Sprint(t.n)
+}
+
+// ServeHTTP has the signature of http.Handler.ServeHTTP.
+func (t *T) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprint(w, t.n)
+}
+
+type visitor interface {
+	visit(n int, depth int) bool
+}
+
+func (t *T) visit(n int, depth int) bool { // ok, implements visitor
+	return n > t.n
+}
+
+func handler(w http.ResponseWriter, r *http.Request) { // ok, used as a value
+	fmt.Fprint(w, "hello")
+}
+
+var handlers = map[string]http.HandlerFunc{
+	"/": handler,
+}
+
+func callback(n int) bool { // ok, used as a value
+	return true
+}
+
+func run(fn func(int) bool) bool {
+	return fn(3)
+}
+
+var _ = run(callback)
+
+func stub(n int) error { // ok, a stub
+	return nil
+}
+
+func unimplemented(n int) { // ok, a stub
+	panic("unimplemented")
+}
+
+func blank(_ int, n int) int { // ok
+	return n
+}
+
+func unnamed(int) {
+	fmt.Println()
+}
@@ -1,4 +1,5 @@
 // Test for redundant type declaration.
+// IGNORE dead-code
 
 // Package foo ...
 package foo